@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/postgresql"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+)
+
+const usage = `usage:
+  client [-redirect-uri uri,...] register <name> [scope...]
+  client list
+  client delete <id>`
+
+func main() {
+	content, err := os.ReadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("Failed to open config file: %v\n", err)
+	}
+	cfg := new(config.Config)
+	if err := json.Unmarshal(content, cfg); err != nil {
+		log.Fatalf("Failed to parse config file: %v\n", err)
+	}
+	redirectURIs := flag.String("redirect-uri", "", "comma separated redirect URIs of a relying party")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatalln(usage)
+	}
+	ctx := context.Background()
+	postgresql, err := postgresql.NewPool(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to start postgresql connection pool: %v\n", err)
+	}
+	defer postgresql.Close()
+	clients := usecase.NewClientUsecase(repository.NewClientPostgreSQL(postgresql), cfg)
+
+	switch {
+	case args[0] == "register" && len(args) >= 2:
+		uris := []string{}
+		if *redirectURIs != "" {
+			uris = strings.Split(*redirectURIs, ",")
+		}
+		id, secret, err := clients.Register(ctx, args[1], args[2:], uris)
+		if err != nil {
+			log.Fatalf("Failed to register client: %v\n", err)
+		}
+		// the secret is only stored hashed, this is the one chance to copy it
+		fmt.Printf("client_id:     %s\nclient_secret: %s\n", id, secret)
+	case args[0] == "list" && len(args) == 1:
+		list, err := clients.List(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list clients: %v\n", err)
+		}
+		for _, client := range list {
+			fmt.Printf(
+				"%s\t%s\t%s\t%s\n",
+				client.ID,
+				client.Name,
+				strings.Join(client.Scopes, " "),
+				strings.Join(client.RedirectURIs, ","),
+			)
+		}
+	case args[0] == "delete" && len(args) == 2:
+		if err := clients.Delete(ctx, args[1]); err != nil {
+			log.Fatalf("Failed to delete client: %v\n", err)
+		}
+	default:
+		log.Fatalln(usage)
+	}
+}
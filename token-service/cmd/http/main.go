@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	stdlog "log"
+	_http "net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -14,12 +18,18 @@ import (
 
 	"github.com/Lab-ICN/backend/token-service/internal/config"
 	_fiber "github.com/Lab-ICN/backend/token-service/internal/fiber"
+	"github.com/Lab-ICN/backend/token-service/internal/geoip"
 	"github.com/Lab-ICN/backend/token-service/internal/http"
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
 	"github.com/Lab-ICN/backend/token-service/internal/postgresql"
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
 	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
 	"github.com/Lab-ICN/backend/token-service/internal/usecase"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
@@ -50,9 +60,84 @@ func main() {
 	r.Use(cors.New())
 	api := r.Group("/backend")
 
+	client := &_http.Client{Timeout: 5 * time.Second}
+	keysCtx, stopKeys := context.WithCancel(ctx)
+	providers, err := newProviders(keysCtx, cfg, client, &log)
+	if err != nil {
+		stdlog.Fatalf("configuring identity providers: %v\n", err)
+	}
 	repo := repository.NewTokenPostgreSQL(postgresql)
-	usecase := usecase.NewTokenUsecase(repo, cfg)
-	http.RegisterHandlers(usecase, cfg, api, validate)
+	users := repository.NewUserHTTP(
+		client,
+		cfg.UserService.URL,
+		cfg.UserService.ApiKey,
+	)
+	states := repository.NewAuthStatePostgreSQL(postgresql)
+	clients := repository.NewClientPostgreSQL(postgresql)
+	codes := repository.NewAuthorizationCodePostgreSQL(postgresql)
+	var countries *geoip.DB
+	if cfg.History.GeoIPFile != "" {
+		countries, err = geoip.Open(cfg.History.GeoIPFile)
+		if err != nil {
+			stdlog.Fatalf("opening geoip database: %v\n", err)
+		}
+	}
+	events := repository.NewEventLog(&log)
+	if cfg.History.WebhookURL != "" {
+		events = repository.NewEventWebhook(client, cfg.History.WebhookURL, cfg.History.WebhookSecret)
+	}
+	loginUsecase := usecase.NewLoginUsecase(
+		repository.NewLoginPostgreSQL(postgresql),
+		users,
+		events,
+		countries,
+		cfg,
+		&log,
+	)
+	mfa := repository.NewMFAPostgreSQL(postgresql)
+	tokenUsecase := usecase.NewTokenUsecase(repo, users, loginUsecase, mfa, cfg, &log)
+	authUsecase := usecase.NewAuthUsecase(tokenUsecase, loginUsecase, states, codes, providers, cfg)
+	var (
+		oidcUsecase usecase.IOIDCUsecase
+		// exchangeKey checks the tokens exchanged for token-service, which
+		// are signed with the OIDC key
+		exchangeKey *rsa.PublicKey
+	)
+	if cfg.OIDC.KeyFile != "" {
+		key, err := readSigningKey(cfg.OIDC.KeyFile)
+		if err != nil {
+			stdlog.Fatalf("reading oidc signing key: %v\n", err)
+		}
+		exchangeKey = &key.PublicKey
+		oidcUsecase = usecase.NewOIDCUsecase(authUsecase, clients, codes, users, repo, key, cfg)
+		http.RegisterOIDCHandlers(oidcUsecase, cfg, api, validate)
+	}
+	limits, closeLimits, err := newRateLimitStorage(cfg, postgresql)
+	if err != nil {
+		stdlog.Fatalf("configuring rate limit store: %v\n", err)
+	}
+	limitUsecase := usecase.NewLimitUsecase(limits, cfg, &log)
+	http.RegisterHandlers(tokenUsecase, loginUsecase, limitUsecase, providers, exchangeKey, cfg, api, validate)
+	http.RegisterAuthHandlers(authUsecase, limitUsecase, cfg, api, validate)
+	if cfg.SMTP.Host != "" {
+		port := cfg.SMTP.Port
+		if port == 0 {
+			port = 587
+		}
+		mailer := repository.NewMailerSMTP(cfg.SMTP.Host, port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		magicLinks := usecase.NewMagicLinkUsecase(
+			tokenUsecase,
+			repository.NewMagicLinkPostgreSQL(postgresql),
+			users,
+			mailer,
+			cfg,
+			&log,
+		)
+		http.RegisterMagicLinkHandlers(magicLinks, limitUsecase, cfg, api, validate)
+	}
+	http.RegisterMFAHandlers(usecase.NewMFAUsecase(mfa, users, cfg), cfg, api, validate)
+	http.RegisterSessionHandlers(usecase.NewSessionUsecase(repo, users, cfg), cfg, api, validate)
+	http.RegisterOAuthHandlers(usecase.NewClientUsecase(clients, cfg), oidcUsecase, cfg, api, validate)
 
 	go func() {
 		if err := r.Listen(fmt.Sprintf("%s:%d", cfg.Address, cfg.Port)); err != nil {
@@ -68,6 +153,13 @@ func main() {
 		func(ctx context.Context) error {
 			return r.Shutdown()
 		},
+		func(ctx context.Context) error {
+			stopKeys()
+			return nil
+		},
+		func(ctx context.Context) error {
+			return closeLimits()
+		},
 		func(ctx context.Context) error {
 			postgresql.Close()
 			return nil
@@ -80,6 +172,97 @@ func main() {
 	stdlog.Println("gracefully shutdown")
 }
 
+// newProviders builds the configured identity providers, keeping the keys of
+// Google ones refreshed in the background until ctx is done.
+func newProviders(
+	ctx context.Context,
+	cfg *config.Config,
+	client *_http.Client,
+	log *zerolog.Logger,
+) (map[string]provider.IProvider, error) {
+	providers := map[string]provider.IProvider{}
+	newGoogle := func(certsURL, clientID, clientSecret string, endpoint provider.Endpoint) provider.IProvider {
+		if certsURL == "" {
+			certsURL = provider.GoogleCertsURL
+		}
+		keys := jwks.NewCache(client, certsURL, log)
+		go keys.Run(ctx)
+		return provider.NewGoogle(client, keys, clientID, clientSecret, endpoint)
+	}
+	if len(cfg.Providers) == 0 && cfg.GoogleClientID != "" {
+		providers[types.ProviderGoogle] = newGoogle("", cfg.GoogleClientID, "", provider.Endpoint{})
+	}
+	for _, idp := range cfg.Providers {
+		if _, ok := providers[idp.Name]; ok {
+			return nil, fmt.Errorf("provider %s configured twice", idp.Name)
+		}
+		endpoint := provider.Endpoint{AuthURL: idp.AuthURL, TokenURL: idp.TokenURL}
+		switch idp.Type {
+		case types.ProviderGoogle:
+			providers[idp.Name] = newGoogle(idp.URL, idp.ClientID, idp.ClientSecret, endpoint)
+		case types.ProviderGitHub:
+			providers[idp.Name] = provider.NewGitHub(client, idp.URL, idp.ClientID, idp.ClientSecret, endpoint)
+		case types.ProviderOIDC:
+			if idp.Issuer == "" {
+				return nil, fmt.Errorf("provider %s has no issuer", idp.Name)
+			}
+			providers[idp.Name] = provider.NewOIDC(client, idp.Issuer, idp.ClientID, idp.ClientSecret, log)
+		default:
+			return nil, fmt.Errorf("provider %s has unknown type %q", idp.Name, idp.Type)
+		}
+	}
+	return providers, nil
+}
+
+// newRateLimitStorage builds the configured rate limit store, along with
+// what closes it on shutdown.
+func newRateLimitStorage(
+	cfg *config.Config,
+	conn *pgxpool.Pool,
+) (repository.IRateLimitStorage, func() error, error) {
+	noop := func() error { return nil }
+	switch cfg.RateLimit.Store {
+	case "", "postgresql":
+		return repository.NewRateLimitPostgreSQL(conn), noop, nil
+	case "memory":
+		return repository.NewRateLimitMemory(), noop, nil
+	case "redis":
+		opts, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing redis url: %w", err)
+		}
+		client := redis.NewClient(opts)
+		return repository.NewRateLimitRedis(client), client.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store %q", cfg.RateLimit.Store)
+	}
+}
+
+// readSigningKey reads a PEM encoded RSA private key, in either PKCS #1 or
+// PKCS #8 form.
+func readSigningKey(path string) (*rsa.PrivateKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key in %s is not rsa", path)
+	}
+	return rsaKey, nil
+}
+
 func gracefulShutdown(
 	ctx context.Context,
 	timeout time.Duration,
@@ -5,8 +5,31 @@ type Config struct {
 	GoogleClientID string
 	PostgreSQL     postgreSQL
 	host           `mapstructure:",squash"`
+	// ProxyHeader is the header the reverse proxy passes client addresses
+	// in, such as X-Forwarded-For, or the proxy's own address is taken for
+	// theirs
+	ProxyHeader string
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxy,
+	// traefik, whose ProxyHeader is believed. Requests from anywhere else
+	// are taken to come from their own address.
+	TrustedProxies []string
 	JWT            jwt
-	Development    bool
+	UserService    userService
+	// Providers are the identity providers users may log in with, picked
+	// by name per request. Without any, GoogleClientID alone enables Google.
+	Providers   []identityProvider
+	Login       login
+	Auth        auth
+	Session     session
+	OAuth       oauth
+	OIDC        oidc
+	Admin       admin
+	History     history
+	RateLimit   rateLimit
+	MFA         mfa
+	SMTP        smtp
+	MagicLink   magicLink
+	Development bool
 }
 
 type host struct {
@@ -26,3 +49,173 @@ type jwt struct {
 	AccessTTL  int
 	RefreshTTL int
 }
+
+type userService struct {
+	URL    string
+	ApiKey string
+}
+
+type identityProvider struct {
+	Name string
+	// Type is one of google, github or oidc
+	Type         string
+	ClientID     string
+	ClientSecret string
+	// Issuer is the OpenID Connect issuer URL, for oidc
+	Issuer string
+	// URL overrides the API root for github and the JWK set URL for google,
+	// pointing it at a local stand-in for offline tests
+	URL string
+	// AuthURL and TokenURL override the code flow endpoints of google and
+	// github, oidc ones come from discovery
+	AuthURL  string
+	TokenURL string
+}
+
+type login struct {
+	// HostedDomains limits Google logins to Workspace accounts of these
+	// domains, any account is let in when empty.
+	HostedDomains []string
+	Domains       []domainRule
+}
+
+// domainRule restricts logins of emails under Domain.
+type domainRule struct {
+	Domain string
+	// Deny refuses every login of the domain
+	Deny bool
+	// RequireHostedDomain only lets in Google accounts managed by the
+	// domain's own Workspace
+	RequireHostedDomain bool
+	// Providers are the provider types the domain may log in with, any
+	// when empty
+	Providers []string
+}
+
+// auth configures the authorization code flow token-service drives itself.
+type auth struct {
+	// BaseURL is where browsers reach token-service's routes, callback URLs
+	// registered with providers are built from it
+	BaseURL string
+	// FrontendURL is where users land once logged in
+	FrontendURL string
+	// StateTTL in minutes is how long users have to authorize
+	StateTTL int
+}
+
+type session struct {
+	// Cookie hands refresh tokens out as HttpOnly cookies instead of in
+	// response bodies, which the authorization code flow always does
+	Cookie bool
+	// RefreshPath is where browsers reach the refresh endpoint, the refresh
+	// cookie is only sent there
+	RefreshPath string
+}
+
+type oauth struct {
+	// ClientTTL in minutes is how long client credentials tokens live
+	ClientTTL int
+	// ExchangeTTL in minutes is the most exchanged tokens live, 5 when
+	// zero, and never past the token they were exchanged for
+	ExchangeTTL int
+	// Audiences are what access tokens may be exchanged for tokens of,
+	// none when empty. Exchanged tokens are signed with OIDC.KeyFile,
+	// tokens are only exchanged with one.
+	Audiences []audience
+}
+
+// audience is a service exchanged tokens are issued for, such as a
+// third-party integration, user-service, which is given the public half of
+// OIDC.KeyFile to check them, or token-service itself.
+type audience struct {
+	// Name is the aud claim of its tokens, which it checks along with
+	// their signature against the published keys
+	Name string
+	// Scopes are those its tokens may be limited to, all of them when an
+	// exchange names none
+	Scopes []string
+}
+
+type oidc struct {
+	// KeyFile is the PEM encoded RSA private key ID tokens are signed with,
+	// token-service only acts as an identity provider with one. The issuer
+	// is Auth.BaseURL.
+	KeyFile string
+	// Provider is the identity provider users log in with on behalf of
+	// relying parties
+	Provider string
+	// CodeTTL in minutes is how long authorization codes stay valid
+	CodeTTL int
+}
+
+type admin struct {
+	// Groups are the user-service groups whose members are admins, there
+	// are none when empty
+	Groups []string
+	// ImpersonationTTL in minutes is how long impersonation tokens live
+	ImpersonationTTL int
+}
+
+type history struct {
+	// GeoIPFile is the offline country database logins are located with, a
+	// CSV of start,end,country address ranges. Without one no login is from
+	// an unusual country.
+	GeoIPFile string
+	// WebhookURL is posted suspicious login events to notify users, which
+	// are only logged without one
+	WebhookURL string
+	// WebhookSecret signs webhook bodies with HMAC-SHA256
+	WebhookSecret string
+	// Limit is how many login attempts history lists, 50 when zero
+	Limit int
+}
+
+// rateLimit limits POST /v1/tokens and PUT /v1/tokens/self per address and
+// per account, the email logging in or the user refreshing.
+type rateLimit struct {
+	// Store is where counts are kept, one of memory, postgresql or redis.
+	// Memory only adds up within one process, defaults to postgresql.
+	Store string
+	// RedisURL is the redis:// URL of the redis store
+	RedisURL string
+	// Window in seconds is what limits count requests over, 60 when zero
+	Window int
+	// IP is how many requests an address may make per window, and Account
+	// how many per account, unlimited when zero
+	IP      int
+	Account int
+	// Failures is how many failed requests of an address or account within
+	// Lockout minutes lock it out for as long, never when zero
+	Failures int
+	// Lockout in minutes is 15 when zero
+	Lockout int
+}
+
+type mfa struct {
+	// Issuer names the service in authenticator apps, "Lab ICN" when empty
+	Issuer string
+	// ChallengeTTL in minutes is how long users have to enter their code
+	// after the first factor, 5 when zero
+	ChallengeTTL int
+}
+
+type smtp struct {
+	// Host is the SMTP relay mail is sent through, magic links are off
+	// without one
+	Host string
+	// Port takes STARTTLS, 587 when zero. Only a relay on the loopback may
+	// go without it.
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type magicLink struct {
+	// URL is the frontend page links point at, which posts the token from
+	// its fragment to token-service. Auth.FrontendURL/login/magic when
+	// empty.
+	URL string
+	// TTL in minutes is how long links stay valid, 15 when zero
+	TTL int
+}
@@ -1,8 +1,11 @@
 package fiber
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/Lab-ICN/backend/token-service/internal/config"
 	"github.com/Lab-ICN/backend/token-service/internal/usecase"
@@ -14,7 +17,13 @@ func New(cfg *config.Config, log *zerolog.Logger) *fiber.App {
 	return fiber.New(fiber.Config{
 		DisableStartupMessage: !cfg.Development,
 		ErrorHandler:          NewErrorHandler(log),
-		Prefork:               true,
+		ProxyHeader:           cfg.ProxyHeader,
+		// Addresses limit requests and lock accounts out, so they are only
+		// taken from the proxy, and only when valid.
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
+		Prefork:                 true,
 		RequestMethods: []string{
 			http.MethodHead,
 			http.MethodOptions,
@@ -32,12 +41,20 @@ func NewErrorHandler(log *zerolog.Logger) func(c *fiber.Ctx, err error) error {
 			Err(err).
 			Str("method", c.Method()).
 			Str("endpoint", c.Path()).
-			Bytes("body", c.Body()).
+			Bytes("body", redact(c)).
 			Msg("error occured")
 		fiberErr := new(fiber.Error)
 		if errors.As(err, &fiberErr) {
 			return c.SendStatus(fiberErr.Code)
 		}
+		oauthErr := new(usecase.OAuthError)
+		if errors.As(err, &oauthErr) {
+			if oauthErr.Code == http.StatusUnauthorized {
+				c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="token"`)
+			}
+			c.Set(fiber.HeaderCacheControl, "no-store")
+			return c.Status(oauthErr.Code).JSON(oauthErr)
+		}
 		uscErr := new(usecase.Error)
 		if errors.As(err, &uscErr) {
 			return c.Status(uscErr.Code).JSON(uscErr)
@@ -45,3 +62,59 @@ func NewErrorHandler(log *zerolog.Logger) func(c *fiber.Ctx, err error) error {
 		return c.SendStatus(http.StatusInternalServerError)
 	}
 }
+
+// secretFields are request fields whose values stay out of the logs, such as
+// those of the OAuth token endpoint.
+var secretFields = []string{
+	"client_secret",
+	"code",
+	"code_verifier",
+	"subject_token",
+	"refresh_token",
+	"refreshToken",
+	"mfaToken",
+	"token",
+	"credential",
+}
+
+const redacted = "[redacted]"
+
+// redact is the request body with the values of secretFields replaced, or
+// nothing for bodies it cannot tell the fields of.
+func redact(c *fiber.Ctx) []byte {
+	body := c.Body()
+	if len(body) == 0 {
+		return body
+	}
+	contentType := c.Get(fiber.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationForm):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+		for _, field := range secretFields {
+			if values.Has(field) {
+				values.Set(field, redacted)
+			}
+		}
+		return []byte(values.Encode())
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+		values := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &values); err != nil {
+			return nil
+		}
+		for _, field := range secretFields {
+			if _, ok := values[field]; ok {
+				values[field] = json.RawMessage(`"` + redacted + `"`)
+			}
+		}
+		content, err := json.Marshal(values)
+		if err != nil {
+			return nil
+		}
+		return content
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package fiber
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandlerRedacts(t *testing.T) {
+	var logs bytes.Buffer
+	log := zerolog.New(&logs)
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler(&log)})
+	app.Post("/v1/oauth/token", func(c *fiber.Ctx) error {
+		return &usecase.OAuthError{Code: http.StatusBadRequest, Reason: "invalid_grant"}
+	})
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+	}{
+		"form": {fiber.MIMEApplicationForm, "grant_type=client_credentials&client_id=seeder&client_secret=hunter2&subject_token=hunter2"},
+		"json": {fiber.MIMEApplicationJSON, `{"grant_type":"client_credentials","client_id":"seeder","client_secret":"hunter2"}`},
+		"text": {fiber.MIMETextPlain, "client_secret=hunter2"},
+	} {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/v1/oauth/token", strings.NewReader(tc.body))
+		req.Header.Set(fiber.HeaderContentType, tc.contentType)
+		resp, err := app.Test(req)
+		assert.Nil(t, err, name)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+		assert.NotContains(t, logs.String(), "hunter2", name)
+		if tc.contentType != fiber.MIMETextPlain {
+			assert.Contains(t, logs.String(), "seeder", name)
+		}
+	}
+}
+
+func TestNewTrustsOnlyProxies(t *testing.T) {
+	log := zerolog.Nop()
+	ip := func(trusted []string, forwarded string) string {
+		cfg := new(config.Config)
+		cfg.ProxyHeader = fiber.HeaderXForwardedFor
+		cfg.TrustedProxies = trusted
+		app := New(cfg, &log)
+		app.Get("/", func(c *fiber.Ctx) error {
+			return c.SendString(c.IP())
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, forwarded)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		body := new(bytes.Buffer)
+		_, err = body.ReadFrom(resp.Body)
+		assert.Nil(t, err)
+		return body.String()
+	}
+	// app.Test connects from 0.0.0.0
+	assert.Equal(t, "0.0.0.0", ip(nil, "10.0.0.1"), "spoofed headers are ignored")
+	assert.Equal(t, "0.0.0.0", ip([]string{"10.0.0.0/8"}, "10.0.0.1"), "only the proxy is believed")
+	assert.Equal(t, "10.0.0.1", ip([]string{"0.0.0.0"}, "10.0.0.1"))
+	assert.Equal(t, "0.0.0.0", ip([]string{"0.0.0.0"}, strings.Repeat("x", 100)), "invalid addresses are not taken")
+}
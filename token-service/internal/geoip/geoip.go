@@ -0,0 +1,91 @@
+// Package geoip locates IP addresses by country from an offline database,
+// a CSV of address ranges as in DB-IP's free country database:
+//
+//	1.0.0.0,1.0.0.255,AU
+//	2001:200::,2001:200:ffff:ffff:ffff:ffff:ffff:ffff,JP
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+type addrRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// DB is a country database, a nil one knows no addresses.
+type DB struct {
+	ranges []addrRange
+}
+
+// Open reads the database from the CSV file at path.
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads the database from CSV, ranges may come in any order but must
+// not overlap.
+func Parse(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	db := new(DB)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading line %d: %w", line, err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d has %d fields, want 3", line, len(record))
+		}
+		start, err := netip.ParseAddr(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("parsing start of line %d: %w", line, err)
+		}
+		end, err := netip.ParseAddr(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing end of line %d: %w", line, err)
+		}
+		db.ranges = append(db.ranges, addrRange{start.Unmap(), end.Unmap(), strings.ToUpper(record[2])})
+	}
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+// Country is the ISO 3166 code of the country ip is in, empty when unknown.
+func (db *DB) Country(ip string) string {
+	if db == nil {
+		return ""
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Compare(db.ranges[i].end) <= 0
+	})
+	if i == len(db.ranges) || addr.Less(db.ranges[i].start) {
+		return ""
+	}
+	return db.ranges[i].country
+}
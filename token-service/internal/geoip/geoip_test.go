@@ -0,0 +1,29 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountry(t *testing.T) {
+	db, err := Parse(strings.NewReader(`1.0.0.0,1.0.0.255,AU
+36.64.0.0,36.95.255.255,id
+2001:200::,2001:200:ffff:ffff:ffff:ffff:ffff:ffff,JP
+`))
+	assert.Nil(t, err)
+	tests := map[string]string{
+		"1.0.0.1":          "AU",
+		"36.80.1.2":        "ID",
+		"::ffff:36.80.1.2": "ID",
+		"2001:200::1":      "JP",
+		"1.0.1.0":          "",
+		"8.8.8.8":          "",
+		"2001:db8::1":      "",
+		"not an ip":        "",
+	}
+	for ip, country := range tests {
+		assert.Equal(t, country, db.Country(ip), ip)
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler struct {
+	usecase  usecase.IAuthUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+func RegisterAuthHandlers(
+	usecase usecase.IAuthUsecase,
+	limits usecase.ILimitUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := AuthHandler{usecase, cfg, validate}
+	v1 := r.Group("/v1/auth")
+	v1.Get("/:provider/start", h.StartHandler)
+	v1.Get("/:provider/callback", h.CallbackHandler)
+	v1.Post("/mfa", RateLimit(limits), h.MFAHandler)
+}
+
+func (h *AuthHandler) StartHandler(c *fiber.Ctx) error {
+	redirect, err := h.usecase.Start(c.Context(), c.Params("provider"), c.Query("redirect"))
+	if err != nil {
+		return err
+	}
+	bindState(c, redirect.State)
+	return c.Redirect(redirect.Location, http.StatusFound)
+}
+
+// CallbackHandler sends the user back to the frontend either way, with the
+// access token, the MFA token or the reason of the refusal in the fragment so
+// none ends up in server logs. The refresh token only ever travels as a cookie. Logins
+// of relying parties end at their redirect URI instead, unless they need the
+// second factor first, whose MFA token is marked with authorize then.
+func (h *AuthHandler) CallbackHandler(c *fiber.Ctx) error {
+	result, err := h.usecase.Callback(c.Context(), c.Params("provider"), &types.CallbackParams{
+		State:      c.Query("state"),
+		Code:       c.Query("code"),
+		Error:      c.Query("error"),
+		BoundState: boundState(c),
+		Device:     *device(c),
+	})
+	if err != nil {
+		return err
+	}
+	if result.Location != "" {
+		return c.Redirect(result.Location, http.StatusFound)
+	}
+	fragment := url.Values{}
+	if result.Error != "" {
+		fragment.Set("error", result.Error)
+	} else if result.MFAToken != "" {
+		fragment.Set("mfaToken", result.MFAToken)
+		if result.Authorize {
+			fragment.Set("authorize", "true")
+		}
+	} else {
+		if err := setSession(c, h.cfg, result.RefreshToken); err != nil {
+			return err
+		}
+		fragment.Set("accessToken", result.AccessToken)
+	}
+	return c.Redirect(
+		strings.TrimSuffix(h.cfg.Auth.FrontendURL, "/")+result.Redirect+"#"+fragment.Encode(),
+		http.StatusFound,
+	)
+}
+
+// MFAHandler finishes the relying party login CallbackHandler answered with
+// an MFA token, telling the frontend where to send the user on to.
+func (h *AuthHandler) MFAHandler(c *fiber.Ctx) error {
+	payload := new(types.MFAParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidMFA,
+			Err:     err,
+		}
+	}
+	result, err := h.usecase.VerifyMFA(c.Context(), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"location": result.Location})
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	cookieRefreshToken = "refresh_token"
+	// cookieCSRFToken is readable by the frontend, which echoes it in
+	// headerCSRFToken to prove the request comes from its own pages.
+	cookieCSRFToken = "csrf_token"
+	headerCSRFToken = "X-CSRF-Token"
+	// cookieAuthState binds a login started with a provider to the browser
+	// that started it.
+	cookieAuthState = "auth_state"
+	// defaultRefreshPath is where browsers reach the refresh endpoint
+	// through the gateway.
+	defaultRefreshPath = "/backend/v1/tokens/self"
+)
+
+// setSession hands the refresh token out as an HttpOnly cookie only sent to
+// the refresh endpoint, along with a fresh CSRF token.
+func setSession(c *fiber.Ctx, cfg *config.Config, refresh string) error {
+	csrf, err := csrfToken()
+	if err != nil {
+		return err
+	}
+	expires := time.Now().Add(time.Duration(cfg.JWT.RefreshTTL) * time.Minute)
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieRefreshToken,
+		Value:    refresh,
+		Path:     refreshPath(cfg),
+		Expires:  expires,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieCSRFToken,
+		Value:    csrf,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	return nil
+}
+
+func clearSession(c *fiber.Ctx, cfg *config.Config) {
+	for name, path := range map[string]string{
+		cookieRefreshToken: refreshPath(cfg),
+		cookieCSRFToken:    "/",
+	} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Path:     path,
+			Expires:  time.Unix(0, 0),
+			Secure:   true,
+			HTTPOnly: name == cookieRefreshToken,
+			SameSite: fiber.CookieSameSiteStrictMode,
+		})
+	}
+}
+
+func refreshPath(cfg *config.Config) string {
+	if cfg.Session.RefreshPath != "" {
+		return cfg.Session.RefreshPath
+	}
+	return defaultRefreshPath
+}
+
+func csrfToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// bindState hands the state of a login out as an HttpOnly cookie the
+// callback has to come back with. Lax lets it along on the top-level
+// redirect back from the provider.
+func bindState(c *fiber.Ctx, state string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieAuthState,
+		Value:    state,
+		Path:     "/",
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// boundState is the state the browser was bound to, which is cleared as
+// each state works once anyway.
+func boundState(c *fiber.Ctx) string {
+	state := c.Cookies(cookieAuthState)
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieAuthState,
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return state
+}
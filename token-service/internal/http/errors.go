@@ -1,6 +1,23 @@
 package http
 
 const (
-	msgInvalidBearer = "bearer header malformed"
-	msgInvalidToken  = "bearer header malformed"
+	msgInvalidBearer        = "bearer header malformed"
+	msgInvalidToken         = "access token is invalid or expired"
+	msgUnknownProvider      = "identity provider not configured"
+	msgInvalidCSRF          = "csrf token missing or mismatched"
+	msgClientToken          = "client tokens do not act for a user"
+	msgWrongAudience        = "access token is issued for another audience"
+	msgExchangedToken       = "exchanged tokens are not taken here"
+	msgMissingScope         = "token lacks scope %s"
+	msgInvalidImpersonation = "userId and reason are required"
+	msgMFARequired          = "requires a login with two-factor authentication"
+	msgImpersonationRefused = "impersonation tokens cannot manage sessions"
+	msgInvalidMFA           = "mfaToken and code are required"
+	msgMissingMFACode       = "code is required"
+	msgInvalidEmail         = "email is required and must be valid"
+	msgMissingToken         = "token is required"
 )
+
+// reasonInvalidCredential is recorded of logins whose credential the provider
+// refused, as the usecase records refused logins by reason.
+const reasonInvalidCredential = "invalidCredential"
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MagicLinkHandler struct {
+	usecase  usecase.IMagicLinkUsecase
+	limits   usecase.ILimitUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+func RegisterMagicLinkHandlers(
+	usecase usecase.IMagicLinkUsecase,
+	limits usecase.ILimitUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := MagicLinkHandler{usecase, limits, cfg, validate}
+	v1 := r.Group("/v1/tokens/magic-link", RateLimit(limits))
+	v1.Post("/", h.SendHandler)
+	v1.Post("/verify", h.VerifyHandler)
+}
+
+// SendHandler accepts every valid email alike, whether a link went out or
+// not, so it tells nobody who is registered.
+func (h *MagicLinkHandler) SendHandler(c *fiber.Ctx) error {
+	payload := new(types.MagicLinkParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidEmail,
+			Err:     err,
+		}
+	}
+	if err := limitAccount(c, h.limits, payload.Email); err != nil {
+		return err
+	}
+	h.usecase.Send(c.Context(), payload.Email)
+	return c.SendStatus(http.StatusAccepted)
+}
+
+func (h *MagicLinkHandler) VerifyHandler(c *fiber.Ctx) error {
+	payload := new(struct {
+		Token string `json:"token" validate:"required"`
+	})
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgMissingToken,
+			Err:     err,
+		}
+	}
+	tokens, err := h.usecase.Verify(c.Context(), payload.Token, device(c))
+	if err != nil {
+		return err
+	}
+	return respondTokens(c, h.cfg, tokens)
+}
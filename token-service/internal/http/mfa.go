@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAHandler struct {
+	usecase  usecase.IMFAUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+func RegisterMFAHandlers(
+	usecase usecase.IMFAUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := MFAHandler{usecase, cfg, validate}
+	v1 := r.Group("/v1/mfa", BearerAuth(cfg.JWT.Key, nil, ""))
+	v1.Post("/totp", h.EnrollHandler)
+	v1.Post("/totp/confirm", h.ConfirmHandler)
+}
+
+func (h *MFAHandler) EnrollHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	amr, _ := c.Locals(keyAMR).([]string)
+	enrollment, err := h.usecase.Enroll(c.Context(), id, amr)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusOK).JSON(enrollment)
+}
+
+func (h *MFAHandler) ConfirmHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	payload := new(struct {
+		Code string `json:"code" validate:"required"`
+	})
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgMissingMFACode,
+			Err:     err,
+		}
+	}
+	codes, err := h.usecase.Confirm(c.Context(), id, payload.Code)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusOK).JSON(fiber.Map{"recoveryCodes": codes})
+}
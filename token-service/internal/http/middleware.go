@@ -1,21 +1,39 @@
 package http
 
 import (
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/Lab-ICN/backend/token-service/internal/types"
 	"github.com/Lab-ICN/backend/token-service/internal/usecase"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	keyClientID = "id"
+	keyClientID  = "id"
+	keySessionID = "sid"
+	keyAMR       = "amr"
+	keyActor     = "act"
+	// audience is the aud claim of tokens exchanged for token-service
+	audience = "token-service"
 )
 
-func BearerAuth(key string) func(c *fiber.Ctx) error {
+// errNoExchangeKey fails parsing exchanged tokens where there is no key to
+// check them with.
+var errNoExchangeKey = errors.New("no key to verify exchanged tokens")
+
+// BearerAuth takes access tokens of users. Tokens exchanged for an audience
+// are signed with the OIDC key, whose public half is exchanged, and only
+// pass when they are for token-service and granted scope. Routes without a
+// scope, or without exchanged, refuse them.
+func BearerAuth(key string, exchanged *rsa.PublicKey, scope string) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		authorization := c.Get(fiber.HeaderAuthorization)
 		bearer := strings.SplitN(authorization, " ", 2)
@@ -26,14 +44,56 @@ func BearerAuth(key string) func(c *fiber.Ctx) error {
 			}
 		}
 		token, err := jwt.Parse(bearer[1], func(token *jwt.Token) (interface{}, error) {
-			return []byte(key), nil
-		})
+			// each kind of token is only checked with its own key
+			if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+				return []byte(key), nil
+			}
+			if exchanged == nil {
+				return nil, errNoExchangeKey
+			}
+			return exchanged, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Name, jwt.SigningMethodRS256.Name}))
 		if err != nil {
-			return &usecase.Error{Code: http.StatusUnauthorized, Message: err.Error()}
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgInvalidToken}
 		}
 		if !token.Valid {
 			return &usecase.Error{Code: http.StatusUnauthorized}
 		}
+		claims, _ := token.Claims.(jwt.MapClaims)
+		if claims["client_id"] != nil {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgClientToken}
+		}
+		aud, _ := claims.GetAudience()
+		if token.Method.Alg() == jwt.SigningMethodRS256.Alg() {
+			if !slices.Contains(aud, audience) {
+				return &usecase.Error{Code: http.StatusUnauthorized, Message: msgWrongAudience}
+			}
+			if scope == "" {
+				return &usecase.Error{Code: http.StatusForbidden, Message: msgExchangedToken}
+			}
+			granted, _ := claims["scope"].(string)
+			if !slices.Contains(strings.Fields(granted), scope) {
+				return &usecase.Error{Code: http.StatusForbidden, Message: fmt.Sprintf(msgMissingScope, scope)}
+			}
+		} else if len(aud) > 0 {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgWrongAudience}
+		}
+		// tokens issued before sessions were tracked carry none, nor do
+		// impersonation tokens. The session is not looked up, so tokens of
+		// revoked sessions pass until they expire.
+		if sid, ok := claims["sid"].(float64); ok {
+			c.Locals(keySessionID, uint64(sid))
+		}
+		c.Locals(keyActor, claims["act"] != nil)
+		amr := []string{}
+		if methods, ok := claims["amr"].([]interface{}); ok {
+			for _, method := range methods {
+				if method, ok := method.(string); ok {
+					amr = append(amr, method)
+				}
+			}
+		}
+		c.Locals(keyAMR, amr)
 		sub, err := token.Claims.GetSubject()
 		if err != nil {
 			return err
@@ -46,3 +106,52 @@ func BearerAuth(key string) func(c *fiber.Ctx) error {
 		return c.Next()
 	}
 }
+
+// RequireMFA only lets access tokens of logins with a second factor through,
+// after BearerAuth.
+func RequireMFA() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		amr, _ := c.Locals(keyAMR).([]string)
+		if !slices.Contains(amr, types.AMRMFA) {
+			return &usecase.Error{
+				Code:    http.StatusForbidden,
+				Message: msgMFARequired,
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RejectImpersonation keeps impersonation tokens, after BearerAuth, away
+// from what only users themselves may do, such as signing out of sessions.
+func RejectImpersonation() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if impersonated, _ := c.Locals(keyActor).(bool); impersonated {
+			return &usecase.Error{
+				Code:    http.StatusForbidden,
+				Message: msgImpersonationRefused,
+			}
+		}
+		return c.Next()
+	}
+}
+
+// CSRF guards endpoints a browser authenticates to with the refresh cookie,
+// requiring the double-submitted CSRF token. Requests without the cookie
+// carry their credentials explicitly and pass.
+func CSRF() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if c.Cookies(cookieRefreshToken) == "" {
+			return c.Next()
+		}
+		cookie := c.Cookies(cookieCSRFToken)
+		header := c.Get(headerCSRFToken)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			return &usecase.Error{
+				Code:    http.StatusForbidden,
+				Message: msgInvalidCSRF,
+			}
+		}
+		return c.Next()
+	}
+}
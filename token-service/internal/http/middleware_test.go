@@ -0,0 +1,223 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	_fiber "github.com/Lab-ICN/backend/token-service/internal/fiber"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRF(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.SendStatus(http.StatusForbidden)
+		},
+	})
+	app.Put("/self", CSRF(), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	cases := []struct {
+		name    string
+		cookies string
+		header  string
+		status  int
+	}{
+		{"no refresh cookie", "", "", http.StatusOK},
+		{"missing header", "refresh_token=r; csrf_token=c", "", http.StatusForbidden},
+		{"mismatched header", "refresh_token=r; csrf_token=c", "x", http.StatusForbidden},
+		{"missing csrf cookie", "refresh_token=r", "c", http.StatusForbidden},
+		{"matching header", "refresh_token=r; csrf_token=c", "c", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPut, "/self", nil)
+		if tc.cookies != "" {
+			req.Header.Set("Cookie", tc.cookies)
+		}
+		if tc.header != "" {
+			req.Header.Set(headerCSRFToken, tc.header)
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := new(config.Config)
+	cfg.RateLimit.IP = 3
+	cfg.RateLimit.Account = 2
+	cfg.RateLimit.Failures = 2
+	limits := usecase.NewLimitUsecase(repository.NewRateLimitMemory(), cfg, &log)
+	app := fiber.New(fiber.Config{
+		ErrorHandler: _fiber.NewErrorHandler(&log),
+		ProxyHeader:  fiber.HeaderXForwardedFor,
+	})
+	app.Post("/login", RateLimit(limits), func(c *fiber.Ctx) error {
+		if err := limitAccount(c, limits, c.Query("account")); err != nil {
+			return err
+		}
+		if c.Query("fail") != "" {
+			return &usecase.Error{Code: http.StatusUnauthorized}
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	login := func(query, ip string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/login?"+query, nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		return resp
+	}
+
+	resp := login("account=a", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(headerLimit), "the account limit is the closer one")
+	assert.Equal(t, "1", resp.Header.Get(headerRemaining))
+	assert.Equal(t, "60", resp.Header.Get(headerReset))
+	assert.Equal(t, http.StatusOK, login("account=a", "10.0.0.1").StatusCode)
+	resp = login("account=a", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get(fiber.HeaderRetryAfter))
+	resp = login("account=b", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "the address is over its limit too")
+	assert.Equal(t, "3", resp.Header.Get(headerLimit))
+	assert.Equal(t, "0", resp.Header.Get(headerRemaining))
+
+	assert.Equal(t, http.StatusUnauthorized, login("account=c&fail=1", "10.0.0.2").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, login("account=c&fail=1", "10.0.0.3").StatusCode)
+	resp = login("account=c", "10.0.0.4")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "the account is locked out")
+	assert.Equal(t, "900", resp.Header.Get(fiber.HeaderRetryAfter))
+	assert.Equal(t, http.StatusOK, login("account=d", "10.0.0.4").StatusCode)
+}
+
+func TestRequireMFA(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.SendStatus(http.StatusForbidden)
+		},
+	})
+	app.Get("/admin", BearerAuth("secret", nil, ""), RequireMFA(), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	for name, tc := range map[string]struct {
+		amr    []string
+		status int
+	}{
+		"no second factor":    {nil, http.StatusForbidden},
+		"recovery code":       {[]string{types.AMRRecovery, types.AMRMFA}, http.StatusOK},
+		"authenticator app":   {[]string{types.AMROTP, types.AMRMFA}, http.StatusOK},
+		"otp without the mfa": {[]string{types.AMROTP}, http.StatusForbidden},
+	} {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, types.AccessClaims{
+			AMR:              tc.amr,
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "2"},
+		}).SignedString([]byte("secret"))
+		assert.Nil(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		resp, err := app.Test(req)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.status, resp.StatusCode, name)
+	}
+}
+
+func TestRejectImpersonation(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.SendStatus(http.StatusForbidden)
+		},
+	})
+	app.Delete("/self", BearerAuth("secret", nil, ""), RejectImpersonation(), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	for name, tc := range map[string]struct {
+		claims types.AccessClaims
+		status int
+	}{
+		"own session":    {types.AccessClaims{Session: 1}, http.StatusOK},
+		"untracked":      {types.AccessClaims{}, http.StatusOK},
+		"impersonation":  {types.AccessClaims{Act: &types.Actor{Subject: "2"}}, http.StatusForbidden},
+		"with a session": {types.AccessClaims{Session: 1, Act: &types.Actor{Subject: "2"}}, http.StatusForbidden},
+	} {
+		tc.claims.Subject = "1"
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, tc.claims).SignedString([]byte("secret"))
+		assert.Nil(t, err)
+		req := httptest.NewRequest(http.MethodDelete, "/self", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		resp, err := app.Test(req)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.status, resp.StatusCode, name)
+	}
+}
+
+func TestBearerAuthAudience(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			uscErr, ok := err.(*usecase.Error)
+			if !ok {
+				return c.SendStatus(http.StatusInternalServerError)
+			}
+			return c.SendStatus(uscErr.Code)
+		},
+	})
+	ok := func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	}
+	app.Get("/history", BearerAuth("secret", &key.PublicKey, "history:read"), ok)
+	app.Get("/sessions", BearerAuth("secret", &key.PublicKey, ""), ok)
+	app.Get("/keyless", BearerAuth("secret", nil, "history:read"), ok)
+	full := func(audience jwt.ClaimStrings) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, types.AccessClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "1", Audience: audience},
+		}).SignedString([]byte("secret"))
+		assert.Nil(t, err)
+		return token
+	}
+	exchanged := func(signer *rsa.PrivateKey, audience, scope string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, types.AccessClaims{
+			Session:          1,
+			Scope:            scope,
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "1", Audience: jwt.ClaimStrings{audience}},
+		}).SignedString(signer)
+		assert.Nil(t, err)
+		return token
+	}
+	for _, tc := range []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{"full token", "/sessions", full(nil), http.StatusOK},
+		{"full token with an audience", "/history", full(jwt.ClaimStrings{"token-service"}), http.StatusUnauthorized},
+		{"exchanged with the scope", "/history", exchanged(key, "token-service", "history:read"), http.StatusOK},
+		{"exchanged without the scope", "/history", exchanged(key, "token-service", "sessions:read"), http.StatusForbidden},
+		{"exchanged elsewhere", "/history", exchanged(key, "calendar", "history:read"), http.StatusUnauthorized},
+		{"exchanged for an unscoped route", "/sessions", exchanged(key, "token-service", "history:read"), http.StatusForbidden},
+		{"exchanged without a key", "/keyless", exchanged(key, "token-service", "history:read"), http.StatusUnauthorized},
+		{"exchanged with another key", "/history", exchanged(other, "token-service", "history:read"), http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tc.token)
+		resp, err := app.Test(req)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OAuthHandler struct {
+	usecase  usecase.IClientUsecase
+	oidc     usecase.IOIDCUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+// RegisterOAuthHandlers mounts the token endpoint, which also takes
+// authorization codes and exchanges access tokens when oidc is set.
+func RegisterOAuthHandlers(
+	usecase usecase.IClientUsecase,
+	oidc usecase.IOIDCUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := OAuthHandler{usecase, oidc, cfg, validate}
+	v1 := r.Group("/v1/oauth")
+	v1.Post("/token", h.TokenHandler)
+}
+
+// TokenHandler is the OAuth token endpoint, taking client credentials from
+// basic auth or, failing that, from the form.
+func (h *OAuthHandler) TokenHandler(c *fiber.Ctx) error {
+	params := &types.TokenParams{
+		ClientID:     c.FormValue("client_id"),
+		ClientSecret: c.FormValue("client_secret"),
+		Scope:        c.FormValue("scope"),
+		Code:         c.FormValue("code"),
+		RedirectURI:  c.FormValue("redirect_uri"),
+		CodeVerifier: c.FormValue("code_verifier"),
+
+		SubjectToken:       c.FormValue("subject_token"),
+		SubjectTokenType:   c.FormValue("subject_token_type"),
+		RequestedTokenType: c.FormValue("requested_token_type"),
+		Audience:           c.FormValue("audience"),
+	}
+	if id, secret, ok := basicAuth(c); ok {
+		params.ClientID, params.ClientSecret = id, secret
+	}
+	var (
+		token *types.OAuthToken
+		err   error
+	)
+	grantType := c.FormValue("grant_type")
+	switch {
+	case grantType == usecase.GrantAuthorizationCode && h.oidc != nil:
+		token, err = h.oidc.Exchange(c.Context(), params)
+	case grantType == usecase.GrantTokenExchange && h.oidc != nil:
+		token, err = h.oidc.ExchangeToken(c.Context(), params)
+	default:
+		token, err = h.usecase.Grant(c.Context(), grantType, params)
+	}
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+	return c.Status(fiber.StatusOK).JSON(token)
+}
+
+// basicAuth reads client credentials from the Authorization header, where
+// RFC 6749 has them form-encoded before joining.
+func basicAuth(c *fiber.Ctx) (string, string, bool) {
+	scheme, credentials, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+	id, err = url.QueryUnescape(id)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err = url.QueryUnescape(secret)
+	if err != nil {
+		return "", "", false
+	}
+	return id, secret, true
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCHandler struct {
+	usecase  usecase.IOIDCUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+func RegisterOIDCHandlers(
+	usecase usecase.IOIDCUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := OIDCHandler{usecase, cfg, validate}
+	r.Get("/.well-known/openid-configuration", h.ConfigurationHandler)
+	r.Get("/.well-known/jwks.json", h.KeysHandler)
+	v1 := r.Group("/v1/oauth")
+	v1.Get("/authorize", h.AuthorizeHandler)
+	v1.Get("/userinfo", h.UserInfoHandler)
+	v1.Post("/userinfo", h.UserInfoHandler)
+}
+
+func (h *OIDCHandler) ConfigurationHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Status(fiber.StatusOK).JSON(h.usecase.Configuration())
+}
+
+func (h *OIDCHandler) KeysHandler(c *fiber.Ctx) error {
+	keys, err := h.usecase.Keys()
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(keys)
+}
+
+func (h *OIDCHandler) AuthorizeHandler(c *fiber.Ctx) error {
+	redirect, err := h.usecase.Authorize(c.Context(), &types.AuthorizationRequest{
+		ClientID:            c.Query("client_id"),
+		RedirectURI:         c.Query("redirect_uri"),
+		ResponseType:        c.Query("response_type"),
+		Scope:               c.Query("scope"),
+		State:               c.Query("state"),
+		Nonce:               c.Query("nonce"),
+		CodeChallenge:       c.Query("code_challenge"),
+		CodeChallengeMethod: c.Query("code_challenge_method"),
+	})
+	if err != nil {
+		return err
+	}
+	if redirect.State != "" {
+		bindState(c, redirect.State)
+	}
+	return c.Redirect(redirect.Location, http.StatusFound)
+}
+
+// UserInfoHandler takes the access token from the bearer header, the one
+// way of RFC 6750 that every relying party supports.
+func (h *OIDCHandler) UserInfoHandler(c *fiber.Ctx) error {
+	bearer, _ := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	info, err := h.usecase.UserInfo(c.Context(), bearer)
+	if err != nil {
+		uscErr := new(usecase.Error)
+		if errors.As(err, &uscErr) && uscErr.Code == http.StatusUnauthorized {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+		}
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(info)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	keyAccount      = "account"
+	keyRateLimit    = "rateLimit"
+	headerLimit     = "RateLimit-Limit"
+	headerRemaining = "RateLimit-Remaining"
+	headerReset     = "RateLimit-Reset"
+)
+
+// RateLimit limits requests per address, and counts failed ones against the
+// address and the account the handler named with limitAccount, locking
+// either out after too many.
+func RateLimit(limits usecase.ILimitUsecase) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		limit, err := limits.Allow(c.Context(), usecase.LimitIP, c.IP())
+		setRateLimit(c, limit, err)
+		if err != nil {
+			return err
+		}
+		err = c.Next()
+		if failed(err) {
+			limits.Fail(c.Context(), usecase.LimitIP, c.IP())
+			if account, ok := c.Locals(keyAccount).(string); ok {
+				limits.Fail(c.Context(), usecase.LimitAccount, account)
+			}
+		}
+		return err
+	}
+}
+
+// limitAccount counts the request against the limit of account, once the
+// handler knows whose it is.
+func limitAccount(c *fiber.Ctx, limits usecase.ILimitUsecase, account string) error {
+	c.Locals(keyAccount, account)
+	limit, err := limits.Allow(c.Context(), usecase.LimitAccount, account)
+	setRateLimit(c, limit, err)
+	return err
+}
+
+// setRateLimit describes the limit closest to refusing the request, the
+// address's or the account's, in RateLimit headers.
+func setRateLimit(c *fiber.Ctx, limit *types.RateLimit, err error) {
+	if limit == nil {
+		return
+	}
+	if shown, ok := c.Locals(keyRateLimit).(*types.RateLimit); ok &&
+		err == nil && shown.Remaining <= limit.Remaining {
+		return
+	}
+	c.Locals(keyRateLimit, limit)
+	reset := strconv.Itoa(max(int(math.Ceil(time.Until(limit.Reset).Seconds())), 0))
+	if limit.Limit > 0 {
+		c.Set(headerLimit, strconv.Itoa(limit.Limit))
+		c.Set(headerRemaining, strconv.Itoa(limit.Remaining))
+		c.Set(headerReset, reset)
+	}
+	if err != nil {
+		c.Set(fiber.HeaderRetryAfter, reset)
+	}
+}
+
+// failed tells whether the request was refused for its credentials, which
+// is what brute forcing them looks like.
+func failed(err error) bool {
+	uscErr := new(usecase.Error)
+	if !errors.As(err, &uscErr) {
+		return false
+	}
+	switch uscErr.Code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
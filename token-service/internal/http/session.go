@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	usecase  usecase.ISessionUsecase
+	cfg      *config.Config
+	validate *validator.Validate
+}
+
+func RegisterSessionHandlers(
+	usecase usecase.ISessionUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := SessionHandler{usecase, cfg, validate}
+	v1 := r.Group("/v1/sessions", BearerAuth(cfg.JWT.Key, nil, ""), RejectImpersonation())
+	v1.Get("/self", h.ListSelfHandler)
+	v1.Delete("/self/:id<int>", h.RevokeSelfHandler)
+	v1.Delete("/self", h.RevokeAllSelfHandler)
+	v1.Get("/:userId<int>", RequireMFA(), h.AdminOnly, h.ListHandler)
+	v1.Delete("/:userId<int>/:id<int>", RequireMFA(), h.AdminOnly, h.RevokeHandler)
+	v1.Delete("/:userId<int>", RequireMFA(), h.AdminOnly, h.RevokeAllHandler)
+}
+
+// AdminOnly lets admins through to the sessions of any user.
+func (h *SessionHandler) AdminOnly(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	if err := h.usecase.AuthorizeAdmin(c.Context(), id); err != nil {
+		return err
+	}
+	return c.Next()
+}
+
+func (h *SessionHandler) ListSelfHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	current, _ := c.Locals(keySessionID).(uint64)
+	sessions, err := h.usecase.List(c.Context(), id, current)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(sessions)
+}
+
+func (h *SessionHandler) RevokeSelfHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	_session, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity, Err: err}
+	}
+	if err := h.usecase.Revoke(c.Context(), id, uint64(_session)); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *SessionHandler) RevokeAllSelfHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	if err := h.usecase.RevokeAll(c.Context(), id); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *SessionHandler) ListHandler(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("userId")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity, Err: err}
+	}
+	sessions, err := h.usecase.List(c.Context(), uint64(_id), 0)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(sessions)
+}
+
+func (h *SessionHandler) RevokeHandler(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("userId")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity, Err: err}
+	}
+	_session, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity, Err: err}
+	}
+	if err := h.usecase.Revoke(c.Context(), uint64(_id), uint64(_session)); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *SessionHandler) RevokeAllHandler(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("userId")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity, Err: err}
+	}
+	if err := h.usecase.RevokeAll(c.Context(), uint64(_id)); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
@@ -1,65 +1,151 @@
 package http
 
 import (
+	"crypto/rsa"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/Lab-ICN/backend/token-service/internal/config"
 	_jwt "github.com/Lab-ICN/backend/token-service/internal/jwt"
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
 	"github.com/Lab-ICN/backend/token-service/internal/usecase"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
-	"google.golang.org/api/idtoken"
 )
 
+// defaultProvider serves requests that name no provider, from before there
+// was a choice.
+const defaultProvider = "google"
+
+// scopeHistoryRead lets tokens exchanged for token-service read the login
+// history of their user.
+const scopeHistoryRead = "history:read"
+
 type Handler struct {
-	usecase  usecase.ITokenUsecase
-	cfg      *config.Config
-	validate *validator.Validate
+	usecase   usecase.ITokenUsecase
+	logins    usecase.ILoginUsecase
+	limits    usecase.ILimitUsecase
+	providers map[string]provider.IProvider
+	cfg       *config.Config
+	validate  *validator.Validate
 }
 
 func RegisterHandlers(
 	usecase usecase.ITokenUsecase,
+	logins usecase.ILoginUsecase,
+	limits usecase.ILimitUsecase,
+	providers map[string]provider.IProvider,
+	exchanged *rsa.PublicKey,
 	cfg *config.Config,
 	r fiber.Router,
 	validate *validator.Validate,
 ) {
-	h := Handler{usecase, cfg, validate}
+	h := Handler{usecase, logins, limits, providers, cfg, validate}
 	v1 := r.Group("/v1/tokens")
-	v1.Post("/", h.GenerateHandler)
+	v1.Post("/", RateLimit(limits), h.GenerateHandler)
 	// FIXME: method patch makes panic
-	v1.Put("/self", h.RefreshHandler)
-	v1.Delete("/self", BearerAuth(cfg.JWT.Key), h.InvalidateHandler)
+	v1.Put("/self", RateLimit(limits), CSRF(), h.RefreshHandler)
+	v1.Delete("/self", CSRF(), BearerAuth(cfg.JWT.Key, nil, ""), RejectImpersonation(), h.InvalidateHandler)
+	v1.Get("/self/history", BearerAuth(cfg.JWT.Key, exchanged, scopeHistoryRead), h.HistoryHandler)
+	v1.Post("/mfa", RateLimit(limits), h.MFAHandler)
+	v1.Post("/impersonate", BearerAuth(cfg.JWT.Key, nil, ""), RequireMFA(), h.ImpersonateHandler)
 }
 
 func (h *Handler) GenerateHandler(c *fiber.Ctx) error {
 	payload := new(struct {
-		Token string `json:"token"`
+		Provider string `json:"provider"`
+		Token    string `json:"token"`
 	})
 	if err := c.BodyParser(payload); err != nil {
 		return &usecase.Error{Code: fiber.StatusBadRequest}
 	}
-	claims, err := idtoken.Validate(c.Context(), payload.Token, h.cfg.GoogleClientID)
+	if payload.Provider == "" {
+		payload.Provider = defaultProvider
+	}
+	idp, ok := h.providers[payload.Provider]
+	if !ok {
+		return &usecase.Error{
+			Code:    http.StatusBadRequest,
+			Message: msgUnknownProvider,
+		}
+	}
+	identity, err := idp.Verify(c.Context(), payload.Token)
 	if err != nil {
-		return &usecase.Error{Code: http.StatusUnauthorized, Err: err}
+		if errors.Is(err, provider.ErrInvalidCredential) {
+			d := device(c)
+			h.logins.Record(c.Context(), &types.LoginAttempt{
+				Provider:  payload.Provider,
+				IP:        d.IP,
+				UserAgent: d.UserAgent,
+				Reason:    reasonInvalidCredential,
+			})
+			return &usecase.Error{Code: http.StatusUnauthorized, Err: err}
+		}
+		return fmt.Errorf("verifying %s credential: %w", payload.Provider, err)
+	}
+	if err := limitAccount(c, h.limits, identity.Email); err != nil {
+		return err
+	}
+	tokens, err := h.usecase.Generate(c.Context(), identity, device(c))
+	if err != nil {
+		return err
+	}
+	return respondTokens(c, h.cfg, tokens)
+}
+
+// MFAHandler finishes the login Generate answered with an MFA challenge.
+// Those of relying parties are answered at /v1/auth/mfa instead.
+func (h *Handler) MFAHandler(c *fiber.Ctx) error {
+	payload := new(types.MFAParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
 	}
-	refresh, access, err := h.usecase.Generate(c.Context(), claims.Claims["email"].(string))
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidMFA,
+			Err:     err,
+		}
+	}
+	tokens, err := h.usecase.VerifyMFA(c.Context(), payload, device(c))
 	if err != nil {
 		return err
 	}
+	return respondTokens(c, h.cfg, tokens)
+}
+
+// respondTokens answers a login with its tokens, or with the MFA token of
+// the challenge standing in for them.
+func respondTokens(c *fiber.Ctx, cfg *config.Config, tokens *types.TokenPair) error {
+	if tokens.MFAToken != "" {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"mfaToken": tokens.MFAToken})
+	}
+	if cfg.Session.Cookie {
+		if err := setSession(c, cfg, tokens.RefreshToken); err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"accessToken": tokens.AccessToken})
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"refreshToken": refresh,
-		"accessToken":  access,
+		"refreshToken": tokens.RefreshToken,
+		"accessToken":  tokens.AccessToken,
 	})
 }
 
+// RefreshHandler takes the refresh token from the cookie when there is one,
+// and from the body otherwise.
 func (h *Handler) RefreshHandler(c *fiber.Ctx) error {
 	payload := new(struct {
 		Token string `json:"refreshToken"`
 	})
-	if err := c.BodyParser(payload); err != nil {
-		return &usecase.Error{Code: fiber.StatusBadRequest}
+	payload.Token = c.Cookies(cookieRefreshToken)
+	if payload.Token == "" {
+		if err := c.BodyParser(payload); err != nil {
+			return &usecase.Error{Code: fiber.StatusBadRequest}
+		}
 	}
 	token, err := _jwt.Validate(payload.Token, h.cfg.JWT.Key)
 	if err != nil {
@@ -67,13 +153,16 @@ func (h *Handler) RefreshHandler(c *fiber.Ctx) error {
 	}
 	sub, err := token.Claims.GetSubject()
 	if err != nil {
-		return fmt.Errorf("getting jwt token subject: %w")
+		return fmt.Errorf("getting jwt token subject: %w", err)
 	}
 	id, err := strconv.ParseUint(sub, 10, 64)
 	if err != nil {
-		return fmt.Errorf("parsing jwt subject of %s to uint64: %w", id, err)
+		return fmt.Errorf("parsing jwt subject of %s to uint64: %w", sub, err)
+	}
+	if err := limitAccount(c, h.limits, sub); err != nil {
+		return err
 	}
-	access, err := h.usecase.Refresh(c.Context(), id)
+	access, err := h.usecase.Refresh(c.Context(), id, payload.Token, c.IP())
 	if err != nil {
 		return err
 	}
@@ -85,8 +174,54 @@ func (h *Handler) InvalidateHandler(c *fiber.Ctx) error {
 	if !ok {
 		return &usecase.Error{Code: http.StatusInternalServerError}
 	}
-	if err := h.usecase.Invalidate(c.Context(), id); err != nil {
+	session, _ := c.Locals(keySessionID).(uint64)
+	if err := h.usecase.Invalidate(c.Context(), id, session); err != nil {
 		return err
 	}
+	if c.Cookies(cookieRefreshToken) != "" {
+		clearSession(c, h.cfg)
+	}
 	return c.SendStatus(http.StatusOK)
 }
+
+func (h *Handler) HistoryHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	attempts, err := h.logins.History(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(attempts)
+}
+
+func (h *Handler) ImpersonateHandler(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return &usecase.Error{Code: http.StatusInternalServerError}
+	}
+	payload := new(types.ImpersonateParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{Code: fiber.StatusBadRequest}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidImpersonation,
+			Err:     err,
+		}
+	}
+	access, err := h.usecase.Impersonate(c.Context(), id, c.IP(), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(fiber.Map{"accessToken": access})
+}
+
+func device(c *fiber.Ctx) *types.Device {
+	return &types.Device{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	}
+}
@@ -0,0 +1,177 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// minMaxAge keeps sets served with no-cache or tiny max-age from being
+	// downloaded on every login.
+	minMaxAge = time.Minute
+	// defaultMaxAge applies when the response says nothing about caching.
+	defaultMaxAge = time.Hour
+	// maxStale is how long past its expiry a set is still trusted while
+	// the provider cannot be reached.
+	maxStale = time.Hour
+	// refetchInterval bounds how often an unknown key ID triggers a
+	// download, so forged tokens cannot hammer the provider.
+	refetchInterval = time.Minute
+	minRetry        = 15 * time.Second
+	maxRetry        = 5 * time.Minute
+)
+
+var (
+	ErrUnavailable = errors.New("signing keys unavailable")
+	ErrUnknownKey  = errors.New("unknown signing key")
+)
+
+// Cache keeps a JWK set for as long as its Cache-Control allows.
+type Cache struct {
+	client *http.Client
+	url    string
+	log    *zerolog.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	maxAge    time.Duration
+	expiresAt time.Time
+
+	fetchMu     sync.Mutex
+	attemptedAt time.Time
+	attemptErr  error
+}
+
+func NewCache(client *http.Client, url string, log *zerolog.Logger) *Cache {
+	return &Cache{client: client, url: url, log: log, now: time.Now}
+}
+
+// Key returns the signing key of ID kid. An expired set is refreshed first,
+// and still served for maxStale if that fails.
+func (c *Cache) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, fresh := c.lookup(kid)
+	if ok && fresh {
+		return key, nil
+	}
+	if err := c.refresh(ctx); err != nil {
+		if ok && c.usable() {
+			c.log.Warn().Err(err).Str("url", c.url).Msg("serving stale jwk set")
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	key, ok, _ = c.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Run refreshes the set ahead of its expiry until ctx is done, so logins
+// rarely wait on the provider.
+func (c *Cache) Run(ctx context.Context) {
+	retry := minRetry
+	for {
+		var wait time.Duration
+		if err := c.fetch(ctx); err != nil {
+			c.log.Warn().Err(err).Str("url", c.url).Msg("refreshing jwk set")
+			wait = retry
+			retry = min(retry*2, maxRetry)
+		} else {
+			c.mu.RLock()
+			wait = c.maxAge * 9 / 10
+			c.mu.RUnlock()
+			retry = minRetry
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Cache) lookup(kid string) (crypto.PublicKey, bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok := c.keys[kid]
+	return key, ok, c.now().Before(c.expiresAt)
+}
+
+func (c *Cache) usable() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now().Before(c.expiresAt.Add(maxStale))
+}
+
+// refresh fetches the set unless that was attempted within refetchInterval,
+// in which case the outcome of that attempt is reused.
+func (c *Cache) refresh(ctx context.Context) error {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	if !c.attemptedAt.IsZero() && c.now().Sub(c.attemptedAt) < refetchInterval {
+		return c.attemptErr
+	}
+	c.attemptedAt = c.now()
+	c.attemptErr = c.fetch(ctx)
+	return c.attemptErr
+}
+
+func (c *Cache) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("creating jwk set request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting jwk set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting jwk set: unexpected status %d", resp.StatusCode)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading jwk set: %w", err)
+	}
+	keys, err := Parse(content)
+	if err != nil {
+		return err
+	}
+	maxAge := parseMaxAge(resp.Header.Get("Cache-Control"))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = keys
+	c.maxAge = maxAge
+	c.expiresAt = c.now().Add(maxAge)
+	return nil
+}
+
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(name, "no-cache") || strings.EqualFold(name, "no-store") {
+			return minMaxAge
+		}
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			break
+		}
+		return max(time.Duration(seconds)*time.Second, minMaxAge)
+	}
+	return defaultMaxAge
+}
@@ -0,0 +1,107 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubJWKS struct {
+	srv   *httptest.Server
+	hits  atomic.Int32
+	down  atomic.Bool
+	clock time.Time
+}
+
+func newStubJWKS(t *testing.T, cacheControl string) *stubJWKS {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	stub := &stubJWKS{clock: time.Now()}
+	stub.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.hits.Add(1)
+		if stub.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": "a",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(stub.srv.Close)
+	return stub
+}
+
+func (s *stubJWKS) cache() *Cache {
+	log := zerolog.Nop()
+	c := NewCache(s.srv.Client(), s.srv.URL, &log)
+	c.now = func() time.Time { return s.clock }
+	return c
+}
+
+func TestCacheHonorsMaxAge(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubJWKS(t, "public, max-age=600")
+	c := stub.cache()
+	_, err := c.Key(ctx, "a")
+	assert.Nil(t, err)
+	_, err = c.Key(ctx, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), stub.hits.Load())
+
+	stub.clock = stub.clock.Add(11 * time.Minute)
+	_, err = c.Key(ctx, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), stub.hits.Load())
+}
+
+func TestCacheServesStale(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubJWKS(t, "max-age=60")
+	c := stub.cache()
+	_, err := c.Key(ctx, "a")
+	assert.Nil(t, err)
+
+	stub.down.Store(true)
+	stub.clock = stub.clock.Add(2 * time.Minute)
+	_, err = c.Key(ctx, "a")
+	assert.Nil(t, err)
+
+	stub.clock = stub.clock.Add(maxStale)
+	_, err = c.Key(ctx, "a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCacheThrottlesUnknownKeys(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubJWKS(t, "max-age=600")
+	c := stub.cache()
+	for range 5 {
+		_, err := c.Key(ctx, "forged")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	}
+	assert.Equal(t, int32(1), stub.hits.Load())
+}
+
+func TestParseMaxAge(t *testing.T) {
+	assert.Equal(t, 5*time.Hour, parseMaxAge("public, max-age=18000, must-revalidate, no-transform"))
+	assert.Equal(t, minMaxAge, parseMaxAge("max-age=5"))
+	assert.Equal(t, minMaxAge, parseMaxAge("no-store"))
+	assert.Equal(t, defaultMaxAge, parseMaxAge(""))
+}
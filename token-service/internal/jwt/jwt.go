@@ -18,10 +18,13 @@ func Validate(token string, secret string) (*jwt.Token, error) {
 	}
 	_token, err := jwt.Parse(token, keyFunc)
 	if err != nil {
-		return nil, fmt.Errorf("parsing jwt token: %w", err)
+		return nil, &usecase.Error{
+			Code: http.StatusUnauthorized,
+			Err:  fmt.Errorf("parsing jwt token: %w", err),
+		}
 	}
 	if !_token.Valid {
-		return nil, usecase.Error{Code: http.StatusUnauthorized}
+		return nil, &usecase.Error{Code: http.StatusUnauthorized}
 	}
 	return _token, nil
 }
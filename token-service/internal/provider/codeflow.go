@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Endpoint overrides where a provider sends users to authorize and where
+// codes are exchanged, the provider's own when left empty.
+type Endpoint struct {
+	AuthURL  string
+	TokenURL string
+}
+
+func (e Endpoint) or(fallback Endpoint) Endpoint {
+	if e.AuthURL == "" {
+		e.AuthURL = fallback.AuthURL
+	}
+	if e.TokenURL == "" {
+		e.TokenURL = fallback.TokenURL
+	}
+	return e
+}
+
+// authCodeURL builds the authorization request of the code flow with an
+// S256 PKCE challenge. nonce is left out when empty.
+func authCodeURL(authURL, clientID, redirectURI, scope, state, nonce, challenge string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {scope},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	if nonce != "" {
+		query.Set("nonce", nonce)
+	}
+	separator := "?"
+	if strings.Contains(authURL, "?") {
+		separator = "&"
+	}
+	return authURL + separator + query.Encode()
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	Error       string `json:"error"`
+}
+
+// exchangeCode redeems an authorization code. Providers refusing the code
+// answer with an OAuth error, which is an invalid credential, not an outage.
+func exchangeCode(
+	ctx context.Context,
+	client *http.Client,
+	tokenURL, clientID, clientSecret, redirectURI, code, verifier string,
+) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating code exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging code: %w", err)
+	}
+	defer resp.Body.Close()
+	token := new(tokenResponse)
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return nil, fmt.Errorf("decoding code exchange response with status %d: %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w: code exchange refused with %s", ErrInvalidCredential, token.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchanging code: unexpected status %d", resp.StatusCode)
+	}
+	return token, nil
+}
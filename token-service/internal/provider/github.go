@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+const defaultGitHubURL = "https://api.github.com"
+
+var githubEndpoint = Endpoint{
+	AuthURL:  "https://github.com/login/oauth/authorize",
+	TokenURL: "https://github.com/login/oauth/access_token",
+}
+
+type github struct {
+	client       *http.Client
+	url          string
+	clientID     string
+	clientSecret string
+	endpoint     Endpoint
+}
+
+// NewGitHub verifies OAuth access tokens issued to the GitHub OAuth app
+// clientID. url is the API root, api.github.com when empty.
+func NewGitHub(
+	client *http.Client,
+	url, clientID, clientSecret string,
+	endpoint Endpoint,
+) IAuthCodeProvider {
+	if url == "" {
+		url = defaultGitHubURL
+	}
+	return &github{
+		client,
+		strings.TrimSuffix(url, "/"),
+		clientID,
+		clientSecret,
+		endpoint.or(githubEndpoint),
+	}
+}
+
+// AuthCodeURL leaves nonce out, GitHub issues no ID token to bind it to.
+func (g *github) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, challenge string) (string, error) {
+	return authCodeURL(
+		g.endpoint.AuthURL,
+		g.clientID,
+		redirectURI,
+		"read:user user:email",
+		state,
+		"",
+		challenge,
+	), nil
+}
+
+func (g *github) Exchange(ctx context.Context, redirectURI, code, verifier, nonce string) (*types.Identity, error) {
+	token, err := exchangeCode(ctx, g.client, g.endpoint.TokenURL, g.clientID, g.clientSecret, redirectURI, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	return g.Verify(ctx, token.AccessToken)
+}
+
+// Verify checks the token against the app first, a token issued to another
+// app would otherwise pass as long as it can read the user.
+func (g *github) Verify(ctx context.Context, credential string) (*types.Identity, error) {
+	body, err := json.Marshal(map[string]string{"access_token": credential})
+	if err != nil {
+		return nil, fmt.Errorf("encoding github token check: %w", err)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/applications/%s/token", g.url, g.clientID),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating github token check request: %w", err)
+	}
+	req.SetBasicAuth(g.clientID, g.clientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("checking github token: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return nil, fmt.Errorf("%w: github token not issued to app", ErrInvalidCredential)
+	default:
+		return nil, fmt.Errorf("checking github token: unexpected status %d", resp.StatusCode)
+	}
+	check := new(struct {
+		User struct {
+			ID        uint64 `json:"id"`
+			Login     string `json:"login"`
+			Name      string `json:"name"`
+			AvatarURL string `json:"avatar_url"`
+		} `json:"user"`
+	})
+	if err := json.NewDecoder(resp.Body).Decode(check); err != nil {
+		return nil, fmt.Errorf("decoding github token check: %w", err)
+	}
+	identity := &types.Identity{
+		Provider: types.ProviderGitHub,
+		Subject:  fmt.Sprint(check.User.ID),
+		Name:     check.User.Name,
+		Picture:  check.User.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = check.User.Login
+	}
+	if err := g.primaryEmail(ctx, credential, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// primaryEmail needs the token to carry the user:email scope, the profile
+// email is empty for users who keep it private.
+func (g *github) primaryEmail(ctx context.Context, credential string, identity *types.Identity) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url+"/user/emails", nil)
+	if err != nil {
+		return fmt.Errorf("creating github emails request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+credential)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting github emails: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return fmt.Errorf("%w: github token cannot read emails", ErrInvalidCredential)
+	default:
+		return fmt.Errorf("requesting github emails: unexpected status %d", resp.StatusCode)
+	}
+	emails := []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
+		return fmt.Errorf("decoding github emails: %w", err)
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: github account has no primary email", ErrInvalidCredential)
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GoogleCertsURL is where Google publishes its ID token signing keys.
+const GoogleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+var googleEndpoint = Endpoint{
+	AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+	TokenURL: "https://oauth2.googleapis.com/token",
+}
+
+type google struct {
+	client       *http.Client
+	keys         *jwks.Cache
+	clientID     string
+	clientSecret string
+	endpoint     Endpoint
+}
+
+// NewGoogle verifies Google ID tokens issued to clientID against the keys in
+// keys, normally a cache of GoogleCertsURL.
+func NewGoogle(
+	client *http.Client,
+	keys *jwks.Cache,
+	clientID, clientSecret string,
+	endpoint Endpoint,
+) IAuthCodeProvider {
+	return &google{client, keys, clientID, clientSecret, endpoint.or(googleEndpoint)}
+}
+
+func (g *google) Verify(ctx context.Context, credential string) (*types.Identity, error) {
+	claims, err := g.parse(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+	return claims.identity(types.ProviderGoogle), nil
+}
+
+func (g *google) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, challenge string) (string, error) {
+	return authCodeURL(
+		g.endpoint.AuthURL,
+		g.clientID,
+		redirectURI,
+		"openid email profile",
+		state,
+		nonce,
+		challenge,
+	), nil
+}
+
+func (g *google) Exchange(ctx context.Context, redirectURI, code, verifier, nonce string) (*types.Identity, error) {
+	token, err := exchangeCode(ctx, g.client, g.endpoint.TokenURL, g.clientID, g.clientSecret, redirectURI, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := g.parse(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := claims.checkNonce(nonce); err != nil {
+		return nil, err
+	}
+	return claims.identity(types.ProviderGoogle), nil
+}
+
+func (g *google) parse(ctx context.Context, idToken string) (*idClaims, error) {
+	claims := new(idClaims)
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return g.keys.Key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(g.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwks.ErrUnavailable) {
+			return nil, fmt.Errorf("verifying google id token: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredential, err)
+	}
+	// Google issues under both spellings of its issuer.
+	if claims.Issuer != "accounts.google.com" && claims.Issuer != "https://accounts.google.com" {
+		return nil, fmt.Errorf("%w: unexpected issuer %s", ErrInvalidCredential, claims.Issuer)
+	}
+	return claims, nil
+}
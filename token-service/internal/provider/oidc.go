@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+)
+
+type oidc struct {
+	client       *http.Client
+	issuer       string
+	clientID     string
+	clientSecret string
+	log          *zerolog.Logger
+
+	mu       sync.Mutex
+	keys     *jwks.Cache
+	endpoint Endpoint
+}
+
+// NewOIDC verifies ID tokens issued to clientID by any OpenID Connect issuer,
+// finding its keys and endpoints through discovery on first use.
+func NewOIDC(
+	client *http.Client,
+	issuer, clientID, clientSecret string,
+	log *zerolog.Logger,
+) IAuthCodeProvider {
+	return &oidc{
+		client:       client,
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		log:          log,
+	}
+}
+
+func (o *oidc) Verify(ctx context.Context, credential string) (*types.Identity, error) {
+	claims, err := o.parse(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+	return claims.identity(types.ProviderOIDC), nil
+}
+
+func (o *oidc) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, challenge string) (string, error) {
+	_, endpoint, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return authCodeURL(
+		endpoint.AuthURL,
+		o.clientID,
+		redirectURI,
+		"openid email profile",
+		state,
+		nonce,
+		challenge,
+	), nil
+}
+
+func (o *oidc) Exchange(ctx context.Context, redirectURI, code, verifier, nonce string) (*types.Identity, error) {
+	_, endpoint, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := exchangeCode(ctx, o.client, endpoint.TokenURL, o.clientID, o.clientSecret, redirectURI, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := o.parse(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := claims.checkNonce(nonce); err != nil {
+		return nil, err
+	}
+	return claims.identity(types.ProviderOIDC), nil
+}
+
+func (o *oidc) parse(ctx context.Context, idToken string) (*idClaims, error) {
+	claims := new(idClaims)
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return o.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(o.issuer),
+		jwt.WithAudience(o.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwks.ErrUnavailable) {
+			return nil, fmt.Errorf("verifying id token of %s: %w", o.issuer, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredential, err)
+	}
+	return claims, nil
+}
+
+func (o *oidc) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keys, _, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Key(ctx, kid)
+}
+
+// discover looks up the issuer's JWK set and endpoints once, an issuer moving
+// them is rare enough to need a restart.
+func (o *oidc) discover(ctx context.Context) (*jwks.Cache, Endpoint, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.keys != nil {
+		return o.keys, o.endpoint, nil
+	}
+	doc := new(struct {
+		Issuer                string `json:"issuer"`
+		JwksURI               string `json:"jwks_uri"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	})
+	if err := getJSON(ctx, o.client, o.issuer+"/.well-known/openid-configuration", doc); err != nil {
+		return nil, Endpoint{}, fmt.Errorf("%w: discovering %s: %w", jwks.ErrUnavailable, o.issuer, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != o.issuer {
+		return nil, Endpoint{}, fmt.Errorf("%w: discovery of %s reports issuer %s", jwks.ErrUnavailable, o.issuer, doc.Issuer)
+	}
+	o.keys = jwks.NewCache(o.client, doc.JwksURI, o.log)
+	o.endpoint = Endpoint{
+		AuthURL:  doc.AuthorizationEndpoint,
+		TokenURL: doc.TokenEndpoint,
+	}
+	return o.keys, o.endpoint, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request to %s: %w", url, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting %s: unexpected status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %s: %w", url, err)
+	}
+	return nil
+}
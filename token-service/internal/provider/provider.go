@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidCredential is returned when a provider refuses to vouch for the
+// credential, as opposed to failing to be reached.
+var ErrInvalidCredential = errors.New("invalid credential")
+
+type IProvider interface {
+	// Verify resolves the identity behind a credential the client obtained
+	// from the provider on its own, an ID token for OIDC providers and an
+	// OAuth access token for GitHub.
+	Verify(ctx context.Context, credential string) (*types.Identity, error)
+}
+
+// IAuthCodeProvider is a provider token-service can send users to itself,
+// through the authorization code flow with PKCE.
+type IAuthCodeProvider interface {
+	IProvider
+	// AuthCodeURL is where to send users to authorize, coming back to
+	// redirectURI with a code. nonce is bound into the ID token of OIDC
+	// providers.
+	AuthCodeURL(ctx context.Context, redirectURI, state, nonce, challenge string) (string, error)
+	// Exchange redeems the code the user came back with.
+	Exchange(ctx context.Context, redirectURI, code, verifier, nonce string) (*types.Identity, error)
+}
+
+// idClaims are the standard OIDC claims of an ID token.
+type idClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+	Locale        string `json:"locale"`
+	HostedDomain  string `json:"hd"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// checkNonce makes sure an ID token from the code flow is the one issued for
+// this very authorization request.
+func (c *idClaims) checkNonce(nonce string) error {
+	if nonce == "" || c.Nonce != nonce {
+		return fmt.Errorf("%w: id token nonce mismatch", ErrInvalidCredential)
+	}
+	return nil
+}
+
+func (c *idClaims) identity(provider string) *types.Identity {
+	return &types.Identity{
+		Provider:      provider,
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: c.EmailVerified,
+		Name:          c.Name,
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+		Picture:       c.Picture,
+		Locale:        c.Locale,
+		HostedDomain:  c.HostedDomain,
+	}
+}
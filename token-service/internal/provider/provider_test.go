@@ -0,0 +1,168 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	stubKeyID    = "stub"
+	stubClientID = "client"
+)
+
+// newStubOIDC serves discovery and a single key JWK set the way an OpenID
+// Connect issuer does.
+func newStubOIDC(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   srv.URL,
+			"jwks_uri": srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": stubKeyID,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	return srv
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = stubKeyID
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err)
+	return signed
+}
+
+func TestOIDCVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	srv := newStubOIDC(t, key)
+	log := zerolog.Nop()
+	p := provider.NewOIDC(srv.Client(), srv.URL, stubClientID, "secret", &log)
+	ctx := context.Background()
+
+	identity, err := p.Verify(ctx, signIDToken(t, key, jwt.MapClaims{
+		"iss":            srv.URL,
+		"aud":            stubClientID,
+		"sub":            "42",
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"email":          "test@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, types.ProviderOIDC, identity.Provider)
+	assert.Equal(t, "test@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Test User", identity.Name)
+
+	_, err = p.Verify(ctx, signIDToken(t, key, jwt.MapClaims{
+		"iss": srv.URL,
+		"aud": "another-client",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	assert.ErrorIs(t, err, provider.ErrInvalidCredential)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	_, err = p.Verify(ctx, signIDToken(t, other, jwt.MapClaims{
+		"iss": srv.URL,
+		"aud": stubClientID,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	assert.ErrorIs(t, err, provider.ErrInvalidCredential)
+}
+
+func TestGoogleVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	srv := newStubOIDC(t, key)
+	log := zerolog.Nop()
+	p := provider.NewGoogle(
+		srv.Client(),
+		jwks.NewCache(srv.Client(), srv.URL+"/jwks", &log),
+		stubClientID,
+		"secret",
+		provider.Endpoint{},
+	)
+	ctx := context.Background()
+
+	identity, err := p.Verify(ctx, signIDToken(t, key, jwt.MapClaims{
+		"iss":   "accounts.google.com",
+		"aud":   stubClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"email": "test@example.com",
+		"hd":    "example.com",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, types.ProviderGoogle, identity.Provider)
+	assert.Equal(t, "example.com", identity.HostedDomain)
+
+	_, err = p.Verify(ctx, signIDToken(t, key, jwt.MapClaims{
+		"iss": srv.URL,
+		"aud": stubClientID,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	assert.ErrorIs(t, err, provider.ErrInvalidCredential)
+}
+
+func TestGitHubVerify(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("POST /applications/{clientID}/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if id != stubClientID || secret != "secret" || body["access_token"] != "valid" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"user": map[string]any{"id": 7, "login": "octocat"},
+		})
+	})
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "other@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	p := provider.NewGitHub(srv.Client(), srv.URL, stubClientID, "secret", provider.Endpoint{})
+	ctx := context.Background()
+
+	identity, err := p.Verify(ctx, "valid")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", identity.Subject)
+	assert.Equal(t, "octocat", identity.Name)
+	assert.Equal(t, "octocat@example.com", identity.Email)
+
+	_, err = p.Verify(ctx, "revoked")
+	assert.ErrorIs(t, err, provider.ErrInvalidCredential)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type authStatePostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+// NewAuthStatePostgreSQL keeps authorization states in the database, so any
+// prefork child can serve the callback of a flow another one started.
+func NewAuthStatePostgreSQL(conn *pgxpool.Pool) IAuthStateStorage {
+	return &authStatePostgreSQL{conn}
+}
+
+// CreateAuthState also sweeps expired states left by abandoned logins.
+func (p *authStatePostgreSQL) CreateAuthState(ctx context.Context, state *types.AuthState) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM auth_states
+        WHERE expires_at < CURRENT_TIMESTAMP;
+    `); err != nil {
+		return fmt.Errorf("deleting expired auth states: %w", err)
+	}
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO auth_states ("state", "provider", "nonce", "verifier", "redirect", "authorization", "expires_at")
+        VALUES (@state, @provider, @nonce, @verifier, @redirect, @authorization, @expires_at);
+    `, pgx.NamedArgs{
+		"state":         state.State,
+		"provider":      state.Provider,
+		"nonce":         state.Nonce,
+		"verifier":      state.Verifier,
+		"redirect":      state.Redirect,
+		"authorization": state.Authorization,
+		"expires_at":    state.ExpiresAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting auth state: %w", err)
+	}
+	return nil
+}
+
+func (p *authStatePostgreSQL) ConsumeAuthState(ctx context.Context, state string) (types.AuthState, error) {
+	row := p.conn.QueryRow(ctx, `
+        DELETE FROM auth_states
+        WHERE state = $1 AND expires_at >= CURRENT_TIMESTAMP
+        RETURNING state, provider, nonce, verifier, redirect, authorization, expires_at;
+    `, state)
+	authState := types.AuthState{}
+	if err := row.Scan(
+		&authState.State,
+		&authState.Provider,
+		&authState.Nonce,
+		&authState.Verifier,
+		&authState.Redirect,
+		&authState.Authorization,
+		&authState.ExpiresAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.AuthState{}, ErrNoRow
+		}
+		return types.AuthState{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return authState, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type clientPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewClientPostgreSQL(conn *pgxpool.Pool) IClientStorage {
+	return &clientPostgreSQL{conn}
+}
+
+func (p *clientPostgreSQL) CreateClient(ctx context.Context, client *types.Client) error {
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO oauth_clients ("id", "name", "secret_hash", "scopes", "redirect_uris")
+        VALUES (@id, @name, @secret_hash, @scopes, @redirect_uris);
+    `, pgx.NamedArgs{
+		"id":            client.ID,
+		"name":          client.Name,
+		"secret_hash":   client.SecretHash,
+		"scopes":        client.Scopes,
+		"redirect_uris": client.RedirectURIs,
+	}); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateRow
+		}
+		return fmt.Errorf("inserting oauth client: %w", err)
+	}
+	return nil
+}
+
+func (p *clientPostgreSQL) GetClient(ctx context.Context, id string) (types.Client, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT id, name, secret_hash, scopes, redirect_uris
+        FROM oauth_clients
+        WHERE id = $1;
+    `, id)
+	client := types.Client{}
+	if err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.SecretHash,
+		&client.Scopes,
+		&client.RedirectURIs,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.Client{}, ErrNoRow
+		}
+		return types.Client{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return client, nil
+}
+
+func (p *clientPostgreSQL) ListClients(ctx context.Context) ([]types.Client, error) {
+	rows, err := p.conn.Query(ctx, `
+        SELECT id, name, scopes, redirect_uris
+        FROM oauth_clients
+        ORDER BY name;
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("selecting oauth clients: %w", err)
+	}
+	defer rows.Close()
+	clients := []types.Client{}
+	for rows.Next() {
+		client := types.Client{}
+		if err := rows.Scan(&client.ID, &client.Name, &client.Scopes, &client.RedirectURIs); err != nil {
+			return nil, fmt.Errorf("scanning query result: %w", err)
+		}
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating oauth clients: %w", err)
+	}
+	return clients, nil
+}
+
+func (p *clientPostgreSQL) DeleteClient(ctx context.Context, id string) error {
+	tag, err := p.conn.Exec(ctx, `
+        DELETE FROM oauth_clients
+        WHERE id = $1;
+    `, id)
+	if err != nil {
+		return fmt.Errorf("deleting oauth client %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type authorizationCodePostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewAuthorizationCodePostgreSQL(conn *pgxpool.Pool) IAuthorizationCodeStorage {
+	return &authorizationCodePostgreSQL{conn}
+}
+
+// CreateAuthorizationCode also sweeps expired codes relying parties never
+// exchanged.
+func (p *authorizationCodePostgreSQL) CreateAuthorizationCode(ctx context.Context, code *types.AuthorizationCode) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM authorization_codes
+        WHERE expires_at < CURRENT_TIMESTAMP;
+    `); err != nil {
+		return fmt.Errorf("deleting expired authorization codes: %w", err)
+	}
+	// a nil slice would be NULL rather than an empty array
+	amr := code.AMR
+	if amr == nil {
+		amr = []string{}
+	}
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO authorization_codes ("code", "client_id", "user_id", "redirect_uri", "scope", "nonce", "challenge", "auth_time", "amr", "expires_at")
+        VALUES (@code, @client_id, @user_id, @redirect_uri, @scope, @nonce, @challenge, @auth_time, @amr, @expires_at);
+    `, pgx.NamedArgs{
+		"code":         code.Code,
+		"client_id":    code.ClientID,
+		"user_id":      code.UserID,
+		"redirect_uri": code.RedirectURI,
+		"scope":        code.Scope,
+		"nonce":        code.Nonce,
+		"challenge":    code.Challenge,
+		"auth_time":    code.AuthTime.UTC(),
+		"amr":          amr,
+		"expires_at":   code.ExpiresAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting authorization code: %w", err)
+	}
+	return nil
+}
+
+func (p *authorizationCodePostgreSQL) ConsumeAuthorizationCode(ctx context.Context, code string) (types.AuthorizationCode, error) {
+	row := p.conn.QueryRow(ctx, `
+        DELETE FROM authorization_codes
+        WHERE code = $1 AND expires_at >= CURRENT_TIMESTAMP
+        RETURNING code, client_id, user_id, redirect_uri, scope, nonce, challenge, auth_time, amr, expires_at;
+    `, code)
+	authCode := types.AuthorizationCode{}
+	if err := row.Scan(
+		&authCode.Code,
+		&authCode.ClientID,
+		&authCode.UserID,
+		&authCode.RedirectURI,
+		&authCode.Scope,
+		&authCode.Nonce,
+		&authCode.Challenge,
+		&authCode.AuthTime,
+		&authCode.AMR,
+		&authCode.ExpiresAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.AuthorizationCode{}, ErrNoRow
+		}
+		return types.AuthorizationCode{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return authCode, nil
+}
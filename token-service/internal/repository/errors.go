@@ -9,6 +9,7 @@ const (
 )
 
 var _errors = []string{
+	ErrDuplicateRow:  "DUPLICATE_ROW",
 	ErrNoRowAffected: "NO_ROW_AFFECTED",
 	ErrNoRow:         "NO_ROW",
 }
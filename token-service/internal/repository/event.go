@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// HeaderSignature carries the hex HMAC-SHA256 of a webhook body under the
+// shared secret, for receivers to check it came from token-service.
+const HeaderSignature = "X-Signature-256"
+
+type eventWebhook struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewEventWebhook posts events as JSON to url, such as the Discord bot's,
+// which notifies the user.
+func NewEventWebhook(client *http.Client, url, secret string) IEventPublisher {
+	return &eventWebhook{client, url, secret}
+}
+
+func (w *eventWebhook) PublishLogin(ctx context.Context, event *types.LoginEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding login event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set(HeaderSignature, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting login event of %d: %w", event.UserID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("posting login event of %d: unexpected status %d", event.UserID, resp.StatusCode)
+	}
+	return nil
+}
+
+type eventLog struct {
+	log *zerolog.Logger
+}
+
+// NewEventLog only logs events, for deployments nothing listens to.
+func NewEventLog(log *zerolog.Logger) IEventPublisher {
+	return &eventLog{log}
+}
+
+func (l *eventLog) PublishLogin(ctx context.Context, event *types.LoginEvent) error {
+	l.log.Warn().
+		Str("type", event.Type).
+		Uint64("user", event.UserID).
+		Str("ip", event.IP).
+		Str("device", event.Device).
+		Str("country", event.Country).
+		Bool("newDevice", event.NewDevice).
+		Bool("unusualCountry", event.UnusualCountry).
+		Msg("suspicious login")
+	return nil
+}
@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+type fakeUser struct {
+	ids    map[string]uint64
+	groups map[uint64][]string
+}
+
+// NewUserFake resolves users from fixed email to ID and ID to group names
+// maps, for tests.
+func NewUserFake(ids map[string]uint64, groups map[uint64][]string) IUserStorage {
+	return &fakeUser{ids, groups}
+}
+
+func (f *fakeUser) GetUserID(ctx context.Context, email string) (uint64, error) {
+	id, ok := f.ids[email]
+	if !ok {
+		return 0, ErrNoRow
+	}
+	return id, nil
+}
+
+func (f *fakeUser) GetUser(ctx context.Context, id uint64) (types.User, error) {
+	for email, userID := range f.ids {
+		if userID == id {
+			return types.User{ID: id, Email: email}, nil
+		}
+	}
+	return types.User{}, ErrNoRow
+}
+
+func (f *fakeUser) GetGroupNames(ctx context.Context, id uint64) ([]string, error) {
+	return f.groups[id], nil
+}
+
+func (f *fakeUser) SyncProfile(ctx context.Context, id uint64, identity *types.Identity) error {
+	return nil
+}
+
+type fakeToken struct {
+	mu             sync.Mutex
+	sessions       map[uint64]types.Session
+	lastID         uint64
+	impersonations []types.Impersonation
+}
+
+// NewTokenFake keeps sessions in memory, for tests.
+func NewTokenFake() ITokenStorage {
+	return &fakeToken{sessions: map[uint64]types.Session{}}
+}
+
+func (f *fakeToken) CreateRefreshToken(ctx context.Context, session *types.Session) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastID++
+	created := *session
+	created.ID = f.lastID
+	created.CreatedAt = time.Now()
+	created.LastUsedAt = created.CreatedAt
+	f.sessions[created.ID] = created
+	return created.ID, nil
+}
+
+func (f *fakeToken) GetSessionByToken(ctx context.Context, token string) (types.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, session := range f.sessions {
+		if session.Token == token {
+			return session, nil
+		}
+	}
+	return types.Session{}, ErrNoRow
+}
+
+func (f *fakeToken) TouchSession(ctx context.Context, id uint64, ip string, groups []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if ok {
+		session.IP = ip
+		session.Groups = groups
+		session.LastUsedAt = time.Now()
+		f.sessions[id] = session
+	}
+	return nil
+}
+
+func (f *fakeToken) ListSessions(ctx context.Context, userID uint64) ([]types.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions := []types.Session{}
+	for _, session := range f.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeToken) DeleteSession(ctx context.Context, userID, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok || session.UserID != userID {
+		return ErrNoRowAffected
+	}
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeToken) DeleteSessions(ctx context.Context, userID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, session := range f.sessions {
+		if session.UserID == userID {
+			delete(f.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeToken) CreateImpersonation(ctx context.Context, impersonation *types.Impersonation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.impersonations = append(f.impersonations, *impersonation)
+	return nil
+}
+
+type fakeAuthState struct {
+	mu     sync.Mutex
+	states map[string]types.AuthState
+}
+
+// NewAuthStateFake keeps authorization states in memory, for tests.
+func NewAuthStateFake() IAuthStateStorage {
+	return &fakeAuthState{states: map[string]types.AuthState{}}
+}
+
+func (f *fakeAuthState) CreateAuthState(ctx context.Context, state *types.AuthState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[state.State] = *state
+	return nil
+}
+
+func (f *fakeAuthState) ConsumeAuthState(ctx context.Context, state string) (types.AuthState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	authState, ok := f.states[state]
+	delete(f.states, state)
+	if !ok || time.Now().After(authState.ExpiresAt) {
+		return types.AuthState{}, ErrNoRow
+	}
+	return authState, nil
+}
+
+type fakeClient struct {
+	mu      sync.Mutex
+	clients map[string]types.Client
+}
+
+// NewClientFake keeps OAuth clients in memory, for tests.
+func NewClientFake() IClientStorage {
+	return &fakeClient{clients: map[string]types.Client{}}
+}
+
+func (f *fakeClient) CreateClient(ctx context.Context, client *types.Client) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.clients[client.ID]; ok {
+		return ErrDuplicateRow
+	}
+	f.clients[client.ID] = *client
+	return nil
+}
+
+func (f *fakeClient) GetClient(ctx context.Context, id string) (types.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client, ok := f.clients[id]
+	if !ok {
+		return types.Client{}, ErrNoRow
+	}
+	return client, nil
+}
+
+func (f *fakeClient) ListClients(ctx context.Context) ([]types.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clients := make([]types.Client, 0, len(f.clients))
+	for _, client := range f.clients {
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (f *fakeClient) DeleteClient(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.clients[id]; !ok {
+		return ErrNoRowAffected
+	}
+	delete(f.clients, id)
+	return nil
+}
+
+type fakeAuthorizationCode struct {
+	mu    sync.Mutex
+	codes map[string]types.AuthorizationCode
+}
+
+// NewAuthorizationCodeFake keeps authorization codes in memory, for tests.
+func NewAuthorizationCodeFake() IAuthorizationCodeStorage {
+	return &fakeAuthorizationCode{codes: map[string]types.AuthorizationCode{}}
+}
+
+func (f *fakeAuthorizationCode) CreateAuthorizationCode(ctx context.Context, code *types.AuthorizationCode) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.codes[code.Code] = *code
+	return nil
+}
+
+func (f *fakeAuthorizationCode) ConsumeAuthorizationCode(ctx context.Context, code string) (types.AuthorizationCode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	authCode, ok := f.codes[code]
+	delete(f.codes, code)
+	if !ok || time.Now().After(authCode.ExpiresAt) {
+		return types.AuthorizationCode{}, ErrNoRow
+	}
+	return authCode, nil
+}
+
+type fakeLogin struct {
+	mu       sync.Mutex
+	attempts []types.LoginAttempt
+}
+
+// NewLoginFake keeps login attempts in memory, for tests.
+func NewLoginFake() ILoginStorage {
+	return &fakeLogin{}
+}
+
+func (f *fakeLogin) CreateLoginAttempt(ctx context.Context, attempt *types.LoginAttempt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	created := *attempt
+	created.ID = uint64(len(f.attempts) + 1)
+	created.CreatedAt = time.Now()
+	f.attempts = append(f.attempts, created)
+	return nil
+}
+
+func (f *fakeLogin) ListLoginAttempts(
+	ctx context.Context,
+	id uint64,
+	email string,
+	limit int,
+) ([]types.LoginAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attempts := []types.LoginAttempt{}
+	for i := len(f.attempts) - 1; i >= 0 && len(attempts) < limit; i-- {
+		attempt := f.attempts[i]
+		if attempt.UserID == id || (attempt.UserID == 0 && email != "" && attempt.Email == email) {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (f *fakeLogin) GetLoginFamiliarity(
+	ctx context.Context,
+	id uint64,
+	userAgent, country string,
+) (types.LoginFamiliarity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	familiarity := types.LoginFamiliarity{}
+	for _, attempt := range f.attempts {
+		if attempt.UserID != id || !attempt.Success {
+			continue
+		}
+		familiarity.Any = true
+		familiarity.Device = familiarity.Device || attempt.UserAgent == userAgent
+		familiarity.Country = familiarity.Country || attempt.Country == country
+	}
+	return familiarity, nil
+}
+
+type fakeEvent struct {
+	mu     sync.Mutex
+	events []types.LoginEvent
+}
+
+// NewEventFake collects published events, for tests to read back with
+// PublishedLogins.
+func NewEventFake() *fakeEvent {
+	return &fakeEvent{}
+}
+
+func (f *fakeEvent) PublishLogin(ctx context.Context, event *types.LoginEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, *event)
+	return nil
+}
+
+func (f *fakeEvent) PublishedLogins() []types.LoginEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.events)
+}
+
+type fakeMFA struct {
+	mu         sync.Mutex
+	factors    map[uint64]types.MFAFactor
+	codes      map[uint64]map[string]bool
+	challenges map[string]types.MFAChallenge
+}
+
+// NewMFAFake keeps factors, recovery codes and challenges in memory, for
+// tests.
+func NewMFAFake() IMFAStorage {
+	return &fakeMFA{
+		factors:    map[uint64]types.MFAFactor{},
+		codes:      map[uint64]map[string]bool{},
+		challenges: map[string]types.MFAChallenge{},
+	}
+}
+
+func (f *fakeMFA) GetMFAFactor(ctx context.Context, userID uint64) (types.MFAFactor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	factor, ok := f.factors[userID]
+	if !ok {
+		return types.MFAFactor{}, ErrNoRow
+	}
+	return factor, nil
+}
+
+func (f *fakeMFA) SetPendingMFASecret(ctx context.Context, userID uint64, secret string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	factor := f.factors[userID]
+	factor.UserID = userID
+	factor.PendingSecret = secret
+	f.factors[userID] = factor
+	return nil
+}
+
+func (f *fakeMFA) ConfirmMFAFactor(
+	ctx context.Context,
+	userID uint64,
+	secret string,
+	step int64,
+	codeHashes []string,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	factor, ok := f.factors[userID]
+	if !ok || factor.PendingSecret != secret {
+		return ErrNoRowAffected
+	}
+	now := time.Now()
+	factor.Secret = secret
+	factor.PendingSecret = ""
+	factor.LastStep = step
+	factor.ConfirmedAt = &now
+	f.factors[userID] = factor
+	f.codes[userID] = map[string]bool{}
+	for _, hash := range codeHashes {
+		f.codes[userID][hash] = false
+	}
+	return nil
+}
+
+func (f *fakeMFA) UseMFAStep(ctx context.Context, userID uint64, step int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	factor, ok := f.factors[userID]
+	if !ok || factor.LastStep >= step {
+		return ErrNoRowAffected
+	}
+	factor.LastStep = step
+	f.factors[userID] = factor
+	return nil
+}
+
+func (f *fakeMFA) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	used, ok := f.codes[userID][codeHash]
+	if !ok || used {
+		return ErrNoRowAffected
+	}
+	f.codes[userID][codeHash] = true
+	return nil
+}
+
+func (f *fakeMFA) CreateMFAChallenge(ctx context.Context, challenge *types.MFAChallenge) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.challenges[challenge.TokenHash] = *challenge
+	return nil
+}
+
+func (f *fakeMFA) GetMFAChallenge(ctx context.Context, tokenHash string) (types.MFAChallenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	challenge, ok := f.challenges[tokenHash]
+	if !ok || challenge.ExpiresAt.Before(time.Now()) {
+		return types.MFAChallenge{}, ErrNoRow
+	}
+	return challenge, nil
+}
+
+func (f *fakeMFA) FailMFAChallenge(ctx context.Context, tokenHash string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	challenge, ok := f.challenges[tokenHash]
+	if !ok {
+		return 0, ErrNoRow
+	}
+	challenge.Attempts++
+	f.challenges[tokenHash] = challenge
+	return challenge.Attempts, nil
+}
+
+func (f *fakeMFA) DeleteMFAChallenge(ctx context.Context, tokenHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.challenges[tokenHash]; !ok {
+		return ErrNoRowAffected
+	}
+	delete(f.challenges, tokenHash)
+	return nil
+}
+
+type fakeMagicLink struct {
+	mu    sync.Mutex
+	links map[string]types.MagicLink
+}
+
+// NewMagicLinkFake keeps magic links in memory, for tests.
+func NewMagicLinkFake() IMagicLinkStorage {
+	return &fakeMagicLink{links: map[string]types.MagicLink{}}
+}
+
+func (f *fakeMagicLink) CreateMagicLink(ctx context.Context, link *types.MagicLink) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.links[link.TokenHash] = *link
+	return nil
+}
+
+func (f *fakeMagicLink) ConsumeMagicLink(ctx context.Context, tokenHash string) (types.MagicLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.links[tokenHash]
+	if !ok || link.ExpiresAt.Before(time.Now()) {
+		return types.MagicLink{}, ErrNoRow
+	}
+	delete(f.links, tokenHash)
+	return link, nil
+}
@@ -1,10 +1,111 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
 
 type ITokenStorage interface {
+	// CreateRefreshToken starts a session, returning its ID.
+	CreateRefreshToken(ctx context.Context, session *types.Session) (uint64, error)
+	GetSessionByToken(ctx context.Context, token string) (types.Session, error)
+	// TouchSession records a refresh of the session from ip, whose access
+	// token carries groups.
+	TouchSession(ctx context.Context, id uint64, ip string, groups []string) error
+	ListSessions(ctx context.Context, userID uint64) ([]types.Session, error)
+	DeleteSession(ctx context.Context, userID, id uint64) error
+	// DeleteSessions signs the user out everywhere.
+	DeleteSessions(ctx context.Context, userID uint64) error
+	CreateImpersonation(ctx context.Context, impersonation *types.Impersonation) error
+}
+
+type IUserStorage interface {
 	GetUserID(ctx context.Context, email string) (uint64, error)
-	CreateRefreshToken(ctx context.Context, email, token string) error
-	DeleteRefreshToken(ctx context.Context, id uint64) error
-	GetRefreshTokenByID(ctx context.Context, id uint64) (string, error)
+	GetUser(ctx context.Context, id uint64) (types.User, error)
+	GetGroupNames(ctx context.Context, id uint64) ([]string, error)
+	SyncProfile(ctx context.Context, id uint64, identity *types.Identity) error
+}
+
+type IAuthStateStorage interface {
+	CreateAuthState(ctx context.Context, state *types.AuthState) error
+	// ConsumeAuthState deletes and returns an unexpired state, so each one
+	// is used once.
+	ConsumeAuthState(ctx context.Context, state string) (types.AuthState, error)
+}
+
+type IClientStorage interface {
+	CreateClient(ctx context.Context, client *types.Client) error
+	GetClient(ctx context.Context, id string) (types.Client, error)
+	ListClients(ctx context.Context) ([]types.Client, error)
+	DeleteClient(ctx context.Context, id string) error
+}
+
+type IAuthorizationCodeStorage interface {
+	CreateAuthorizationCode(ctx context.Context, code *types.AuthorizationCode) error
+	// ConsumeAuthorizationCode deletes and returns an unexpired code, so
+	// each one is exchanged once.
+	ConsumeAuthorizationCode(ctx context.Context, code string) (types.AuthorizationCode, error)
+}
+
+type ILoginStorage interface {
+	CreateLoginAttempt(ctx context.Context, attempt *types.LoginAttempt) error
+	// ListLoginAttempts is the latest attempts of user id, including failed
+	// ones of their email that never resolved to them.
+	ListLoginAttempts(ctx context.Context, id uint64, email string, limit int) ([]types.LoginAttempt, error)
+	// GetLoginFamiliarity tells whether user id logged in before with the
+	// exact userAgent and from country.
+	GetLoginFamiliarity(ctx context.Context, id uint64, userAgent, country string) (types.LoginFamiliarity, error)
+}
+
+type IEventPublisher interface {
+	PublishLogin(ctx context.Context, event *types.LoginEvent) error
+}
+
+// IRateLimitStorage counts hits of keys over fixed windows. The first hit of
+// a key starts its window, and counting starts over once it ends.
+type IRateLimitStorage interface {
+	// Increment counts a hit of key, returning the hits so far in the window
+	// and when it ends.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+	// Get is the hits of key so far without counting one, zero once the
+	// window ended.
+	Get(ctx context.Context, key string) (int, time.Time, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type IMFAStorage interface {
+	// GetMFAFactor is ErrNoRow for users who never enrolled.
+	GetMFAFactor(ctx context.Context, userID uint64) (types.MFAFactor, error)
+	// SetPendingMFASecret enrolls secret for the user to confirm, leaving a
+	// confirmed factor in place until they do.
+	SetPendingMFASecret(ctx context.Context, userID uint64, secret string) error
+	// ConfirmMFAFactor makes the pending secret the factor, used up to step,
+	// with a fresh set of recovery codes. It is ErrNoRowAffected when the
+	// pending secret is no longer secret.
+	ConfirmMFAFactor(ctx context.Context, userID uint64, secret string, step int64, codeHashes []string) error
+	// UseMFAStep is ErrNoRowAffected when a code of step or a later one was
+	// already used.
+	UseMFAStep(ctx context.Context, userID uint64, step int64) error
+	// UseRecoveryCode is ErrNoRowAffected when the code is unknown or used.
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error
+	CreateMFAChallenge(ctx context.Context, challenge *types.MFAChallenge) error
+	// GetMFAChallenge is ErrNoRow for unknown or expired challenges.
+	GetMFAChallenge(ctx context.Context, tokenHash string) (types.MFAChallenge, error)
+	// FailMFAChallenge counts a wrong code, returning how many there were.
+	FailMFAChallenge(ctx context.Context, tokenHash string) (int, error)
+	// DeleteMFAChallenge is ErrNoRowAffected when it is already gone.
+	DeleteMFAChallenge(ctx context.Context, tokenHash string) error
+}
+
+type IMagicLinkStorage interface {
+	CreateMagicLink(ctx context.Context, link *types.MagicLink) error
+	// ConsumeMagicLink deletes the link, so it only logs in once. It is
+	// ErrNoRow for unknown, used or expired links.
+	ConsumeMagicLink(ctx context.Context, tokenHash string) (types.MagicLink, error)
+}
+
+type IMailer interface {
+	Send(ctx context.Context, mail *types.Mail) error
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type loginPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewLoginPostgreSQL(conn *pgxpool.Pool) ILoginStorage {
+	return &loginPostgreSQL{conn}
+}
+
+func (p *loginPostgreSQL) CreateLoginAttempt(ctx context.Context, attempt *types.LoginAttempt) error {
+	var userID *uint64
+	if attempt.UserID != 0 {
+		userID = &attempt.UserID
+	}
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO login_attempts (
+            "user_id", "email", "provider", "ip", "user_agent", "device",
+            "country", "success", "reason", "new_device", "unusual_country"
+        )
+        VALUES (
+            @user_id, @email, @provider, @ip, @user_agent, @device,
+            @country, @success, @reason, @new_device, @unusual_country
+        );
+    `, pgx.NamedArgs{
+		"user_id":         userID,
+		"email":           attempt.Email,
+		"provider":        attempt.Provider,
+		"ip":              attempt.IP,
+		"user_agent":      attempt.UserAgent,
+		"device":          attempt.Device,
+		"country":         attempt.Country,
+		"success":         attempt.Success,
+		"reason":          attempt.Reason,
+		"new_device":      attempt.NewDevice,
+		"unusual_country": attempt.UnusualCountry,
+	}); err != nil {
+		return fmt.Errorf("inserting login attempt: %w", err)
+	}
+	return nil
+}
+
+func (p *loginPostgreSQL) ListLoginAttempts(
+	ctx context.Context,
+	id uint64,
+	email string,
+	limit int,
+) ([]types.LoginAttempt, error) {
+	rows, err := p.conn.Query(ctx, `
+        SELECT id, COALESCE(user_id, 0), email, provider, ip, user_agent, device,
+               TRIM(country), success, reason, new_device, unusual_country, created_at
+        FROM login_attempts
+        WHERE user_id = @id OR (user_id IS NULL AND email = @email AND email <> '')
+        ORDER BY created_at DESC
+        LIMIT @limit;
+    `, pgx.NamedArgs{
+		"id":    id,
+		"email": email,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("selecting login attempts of %d: %w", id, err)
+	}
+	defer rows.Close()
+	attempts := []types.LoginAttempt{}
+	for rows.Next() {
+		attempt := types.LoginAttempt{}
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.UserID,
+			&attempt.Email,
+			&attempt.Provider,
+			&attempt.IP,
+			&attempt.UserAgent,
+			&attempt.Device,
+			&attempt.Country,
+			&attempt.Success,
+			&attempt.Reason,
+			&attempt.NewDevice,
+			&attempt.UnusualCountry,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning query result: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating login attempts of %d: %w", id, err)
+	}
+	return attempts, nil
+}
+
+func (p *loginPostgreSQL) GetLoginFamiliarity(
+	ctx context.Context,
+	id uint64,
+	userAgent, country string,
+) (types.LoginFamiliarity, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT
+            COUNT(*) > 0,
+            COUNT(*) FILTER (WHERE user_agent = @user_agent) > 0,
+            COUNT(*) FILTER (WHERE country = @country) > 0
+        FROM login_attempts
+        WHERE user_id = @id AND success;
+    `, pgx.NamedArgs{
+		"id":         id,
+		"user_agent": userAgent,
+		"country":    country,
+	})
+	familiarity := types.LoginFamiliarity{}
+	if err := row.Scan(&familiarity.Any, &familiarity.Device, &familiarity.Country); err != nil {
+		return types.LoginFamiliarity{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return familiarity, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type magicLinkPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewMagicLinkPostgreSQL(conn *pgxpool.Pool) IMagicLinkStorage {
+	return &magicLinkPostgreSQL{conn}
+}
+
+// CreateMagicLink also sweeps expired links nobody followed.
+func (p *magicLinkPostgreSQL) CreateMagicLink(ctx context.Context, link *types.MagicLink) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM magic_links
+        WHERE expires_at < CURRENT_TIMESTAMP;
+    `); err != nil {
+		return fmt.Errorf("deleting expired magic links: %w", err)
+	}
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO magic_links ("token_hash", "email", "expires_at")
+        VALUES (@token_hash, @email, @expires_at);
+    `, pgx.NamedArgs{
+		"token_hash": link.TokenHash,
+		"email":      link.Email,
+		"expires_at": link.ExpiresAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting magic link: %w", err)
+	}
+	return nil
+}
+
+func (p *magicLinkPostgreSQL) ConsumeMagicLink(ctx context.Context, tokenHash string) (types.MagicLink, error) {
+	row := p.conn.QueryRow(ctx, `
+        DELETE FROM magic_links
+        WHERE token_hash = $1 AND expires_at >= CURRENT_TIMESTAMP
+        RETURNING token_hash, email, expires_at;
+    `, tokenHash)
+	link := types.MagicLink{}
+	if err := row.Scan(&link.TokenHash, &link.Email, &link.ExpiresAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.MagicLink{}, ErrNoRow
+		}
+		return types.MagicLink{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return link, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+type mailerSMTP struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// NewMailerSMTP sends mail through an SMTP relay, upgrading to TLS with
+// STARTTLS, which only a relay on the loopback may go without. Without a
+// username it sends unauthenticated, as to a local relay or a stand-in for
+// tests.
+func NewMailerSMTP(host string, port int, username, password, from string) IMailer {
+	return &mailerSMTP{host, port, username, password, from}
+}
+
+func (m *mailerSMTP) Send(ctx context.Context, mail *types.Mail) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, strconv.Itoa(m.port)))
+	if err != nil {
+		return fmt.Errorf("dialing smtp relay: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("greeting smtp relay: %w", err)
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("starting tls: %w", err)
+		}
+	} else if !isLoopback(m.host) {
+		return fmt.Errorf("smtp relay %s does not offer STARTTLS", m.host)
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("authenticating to smtp relay: %w", err)
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("sending from %s: %w", m.from, err)
+	}
+	if err := client.Rcpt(mail.To); err != nil {
+		return fmt.Errorf("sending to %s: %w", mail.To, err)
+	}
+	message, err := m.message(mail)
+	if err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("starting mail data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("writing mail data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("ending mail data: %w", err)
+	}
+	return client.Quit()
+}
+
+func (m *mailerSMTP) message(mail *types.Mail) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("read random bytes: %w", err)
+	}
+	_, domain, _ := strings.Cut(m.from, "@")
+	headers := [][2]string{
+		{"From", m.from},
+		{"To", mail.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", mail.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + hex.EncodeToString(id) + "@" + domain + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+	}
+	var b strings.Builder
+	for _, header := range headers {
+		b.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(mail.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
+
+// isLoopback tells whether host is this machine, which mail to never
+// crosses a network.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
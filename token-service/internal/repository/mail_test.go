@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLoopback(t *testing.T) {
+	for host, loopback := range map[string]bool{
+		"localhost":        true,
+		"127.0.0.1":        true,
+		"::1":              true,
+		"smtp.example.com": false,
+		"10.0.0.25":        false,
+	} {
+		assert.Equal(t, loopback, isLoopback(host), host)
+	}
+}
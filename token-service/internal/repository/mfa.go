@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type mfaPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewMFAPostgreSQL(conn *pgxpool.Pool) IMFAStorage {
+	return &mfaPostgreSQL{conn}
+}
+
+func (p *mfaPostgreSQL) GetMFAFactor(ctx context.Context, userID uint64) (types.MFAFactor, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT user_id, COALESCE(secret, ''), COALESCE(pending_secret, ''), last_step, confirmed_at
+        FROM mfa_factors
+        WHERE user_id = $1;
+    `, userID)
+	factor := types.MFAFactor{}
+	if err := row.Scan(
+		&factor.UserID,
+		&factor.Secret,
+		&factor.PendingSecret,
+		&factor.LastStep,
+		&factor.ConfirmedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.MFAFactor{}, ErrNoRow
+		}
+		return types.MFAFactor{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return factor, nil
+}
+
+func (p *mfaPostgreSQL) SetPendingMFASecret(ctx context.Context, userID uint64, secret string) error {
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO mfa_factors ("user_id", "pending_secret")
+        VALUES (@user_id, @secret)
+        ON CONFLICT ("user_id") DO UPDATE SET "pending_secret" = EXCLUDED.pending_secret;
+    `, pgx.NamedArgs{
+		"user_id": userID,
+		"secret":  secret,
+	}); err != nil {
+		return fmt.Errorf("enrolling mfa factor of %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (p *mfaPostgreSQL) ConfirmMFAFactor(
+	ctx context.Context,
+	userID uint64,
+	secret string,
+	step int64,
+	codeHashes []string,
+) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `
+        UPDATE mfa_factors
+        SET secret = pending_secret,
+            pending_secret = NULL,
+            last_step = @step,
+            confirmed_at = CURRENT_TIMESTAMP
+        WHERE user_id = @user_id AND pending_secret = @secret;
+    `, pgx.NamedArgs{
+		"user_id": userID,
+		"secret":  secret,
+		"step":    step,
+	})
+	if err != nil {
+		return fmt.Errorf("confirming mfa factor of %d: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	if _, err := tx.Exec(ctx, `
+        DELETE FROM mfa_recovery_codes
+        WHERE user_id = $1;
+    `, userID); err != nil {
+		return fmt.Errorf("deleting recovery codes of %d: %w", userID, err)
+	}
+	if _, err := tx.Exec(ctx, `
+        INSERT INTO mfa_recovery_codes ("user_id", "code_hash")
+        SELECT $1, UNNEST($2::TEXT[]);
+    `, userID, codeHashes); err != nil {
+		return fmt.Errorf("inserting recovery codes of %d: %w", userID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *mfaPostgreSQL) UseMFAStep(ctx context.Context, userID uint64, step int64) error {
+	tag, err := p.conn.Exec(ctx, `
+        UPDATE mfa_factors
+        SET last_step = $2
+        WHERE user_id = $1 AND last_step < $2;
+    `, userID, step)
+	if err != nil {
+		return fmt.Errorf("using mfa step of %d: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
+
+func (p *mfaPostgreSQL) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	tag, err := p.conn.Exec(ctx, `
+        UPDATE mfa_recovery_codes
+        SET used_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+    `, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("using recovery code of %d: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
+
+// CreateMFAChallenge also sweeps expired challenges never answered.
+func (p *mfaPostgreSQL) CreateMFAChallenge(ctx context.Context, challenge *types.MFAChallenge) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM mfa_challenges
+        WHERE expires_at < CURRENT_TIMESTAMP;
+    `); err != nil {
+		return fmt.Errorf("deleting expired mfa challenges: %w", err)
+	}
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO mfa_challenges ("token_hash", "user_id", "authorization", "expires_at")
+        VALUES (@token_hash, @user_id, @authorization, @expires_at);
+    `, pgx.NamedArgs{
+		"token_hash":    challenge.TokenHash,
+		"user_id":       challenge.UserID,
+		"authorization": challenge.Authorization,
+		"expires_at":    challenge.ExpiresAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting mfa challenge of %d: %w", challenge.UserID, err)
+	}
+	return nil
+}
+
+func (p *mfaPostgreSQL) GetMFAChallenge(ctx context.Context, tokenHash string) (types.MFAChallenge, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT token_hash, user_id, authorization, attempts, expires_at
+        FROM mfa_challenges
+        WHERE token_hash = $1 AND expires_at >= CURRENT_TIMESTAMP;
+    `, tokenHash)
+	challenge := types.MFAChallenge{}
+	if err := row.Scan(
+		&challenge.TokenHash,
+		&challenge.UserID,
+		&challenge.Authorization,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.MFAChallenge{}, ErrNoRow
+		}
+		return types.MFAChallenge{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return challenge, nil
+}
+
+func (p *mfaPostgreSQL) FailMFAChallenge(ctx context.Context, tokenHash string) (int, error) {
+	row := p.conn.QueryRow(ctx, `
+        UPDATE mfa_challenges
+        SET attempts = attempts + 1
+        WHERE token_hash = $1
+        RETURNING attempts;
+    `, tokenHash)
+	var attempts int
+	if err := row.Scan(&attempts); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNoRow
+		}
+		return 0, fmt.Errorf("scanning query result: %w", err)
+	}
+	return attempts, nil
+}
+
+func (p *mfaPostgreSQL) DeleteMFAChallenge(ctx context.Context, tokenHash string) error {
+	tag, err := p.conn.Exec(ctx, `
+        DELETE FROM mfa_challenges
+        WHERE token_hash = $1;
+    `, tokenHash)
+	if err != nil {
+		return fmt.Errorf("deleting mfa challenge: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
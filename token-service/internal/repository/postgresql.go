@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Lab-ICN/backend/token-service/internal/types"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,56 +18,148 @@ func NewTokenPostgreSQL(conn *pgxpool.Pool) ITokenStorage {
 	return &postgresql{conn}
 }
 
-func (p *postgresql) GetUserID(ctx context.Context, email string) (uint64, error) {
+func (p *postgresql) CreateRefreshToken(ctx context.Context, session *types.Session) (uint64, error) {
+	// a nil slice would be NULL rather than an empty array
+	amr := session.AMR
+	if amr == nil {
+		amr = []string{}
+	}
+	groups := session.Groups
+	if groups == nil {
+		groups = []string{}
+	}
 	row := p.conn.QueryRow(ctx, `
-        SELECT id
-        FROM users
-        WHERE email = $1;
-    `, email)
+        INSERT INTO refresh_tokens ("user_id", "token", "user_agent", "ip", "amr", "groups", "expires_at")
+        VALUES (@user_id, @token, @user_agent, @ip, @amr, @groups, @expires_at)
+        RETURNING id;
+    `, pgx.NamedArgs{
+		"user_id":    session.UserID,
+		"token":      session.Token,
+		"user_agent": session.UserAgent,
+		"ip":         session.IP,
+		"amr":        amr,
+		"groups":     groups,
+		"expires_at": session.ExpiresAt,
+	})
 	var id uint64
 	if err := row.Scan(&id); err != nil {
-		if errors.Is(pgx.ErrNoRows, err) {
-			return 0, ErrNoRow
-		}
-		return 0, fmt.Errorf("scanning query result: %w", err)
+		return 0, fmt.Errorf("inserting refresh token for id %d: %w", session.UserID, err)
 	}
 	return id, nil
 }
 
-func (p *postgresql) CreateRefreshToken(ctx context.Context, email string, token string) error {
-	if _, err := p.conn.Exec(ctx, `
-        UPDATE users
-        SET refresh_token = $2
-        WHERE email = $1;
-    `, email, token); err != nil {
-		return err
+func (p *postgresql) GetSessionByToken(ctx context.Context, token string) (types.Session, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT id, user_id, token, user_agent, ip, amr, groups, created_at, last_used_at, expires_at
+        FROM refresh_tokens
+        WHERE token = $1;
+    `, token)
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.Session{}, ErrNoRow
+		}
+		return types.Session{}, fmt.Errorf("scanning query result: %w", err)
 	}
-	return nil
+	return session, nil
 }
 
-func (p *postgresql) DeleteRefreshToken(ctx context.Context, id uint64) error {
+func (p *postgresql) TouchSession(ctx context.Context, id uint64, ip string, groups []string) error {
+	if groups == nil {
+		groups = []string{}
+	}
 	if _, err := p.conn.Exec(ctx, `
-        UPDATE users
-        SET refresh_token = NULL
+        UPDATE refresh_tokens
+        SET ip = $2,
+            groups = $3,
+            last_used_at = CURRENT_TIMESTAMP
         WHERE id = $1;
-    `, id); err != nil {
-		return err
+    `, id, ip, groups); err != nil {
+		return fmt.Errorf("touching session %d: %w", id, err)
 	}
 	return nil
 }
 
-func (p *postgresql) GetRefreshTokenByID(ctx context.Context, id uint64) (string, error) {
-	row := p.conn.QueryRow(ctx, `
-        SELECT refresh_token
-        FROM users
-        WHERE id = $1;
-    `, id)
-	var token string
-	if err := row.Scan(&token); err != nil {
-		if errors.Is(pgx.ErrNoRows, err) {
-			return "", ErrNoRow
+// ListSessions leaves out sessions whose refresh token expired.
+func (p *postgresql) ListSessions(ctx context.Context, userID uint64) ([]types.Session, error) {
+	rows, err := p.conn.Query(ctx, `
+        SELECT id, user_id, token, user_agent, ip, amr, groups, created_at, last_used_at, expires_at
+        FROM refresh_tokens
+        WHERE user_id = $1
+          AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+        ORDER BY last_used_at DESC;
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("selecting sessions of %d: %w", userID, err)
+	}
+	defer rows.Close()
+	sessions := []types.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning query result: %w", err)
 		}
-		return "", fmt.Errorf("scanning query result: %w", err)
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sessions of %d: %w", userID, err)
+	}
+	return sessions, nil
+}
+
+func (p *postgresql) DeleteSession(ctx context.Context, userID, id uint64) error {
+	tag, err := p.conn.Exec(ctx, `
+        DELETE FROM refresh_tokens
+        WHERE id = $1 AND user_id = $2;
+    `, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting session %d of %d: %w", id, userID, err)
 	}
-	return token, nil
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
+
+func (p *postgresql) DeleteSessions(ctx context.Context, userID uint64) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM refresh_tokens
+        WHERE user_id = $1;
+    `, userID); err != nil {
+		return fmt.Errorf("deleting refresh tokens for id %d: %w", userID, err)
+	}
+	return nil
+}
+
+func scanSession(row pgx.Row) (types.Session, error) {
+	session := types.Session{}
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Token,
+		&session.UserAgent,
+		&session.IP,
+		&session.AMR,
+		&session.Groups,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+	)
+	return session, err
+}
+
+func (p *postgresql) CreateImpersonation(ctx context.Context, impersonation *types.Impersonation) error {
+	if _, err := p.conn.Exec(ctx, `
+        INSERT INTO impersonations ("admin_id", "user_id", "reason", "ip", "expires_at")
+        VALUES (@admin_id, @user_id, @reason, @ip, @expires_at);
+    `, pgx.NamedArgs{
+		"admin_id":   impersonation.AdminID,
+		"user_id":    impersonation.UserID,
+		"reason":     impersonation.Reason,
+		"ip":         impersonation.IP,
+		"expires_at": impersonation.ExpiresAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting impersonation of %d by %d: %w", impersonation.UserID, impersonation.AdminID, err)
+	}
+	return nil
 }
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type rateLimitPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewRateLimitPostgreSQL(conn *pgxpool.Pool) IRateLimitStorage {
+	return &rateLimitPostgreSQL{conn}
+}
+
+// Increment also sweeps keys whose window ended a while ago.
+func (p *rateLimitPostgreSQL) Increment(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+) (int, time.Time, error) {
+	now := time.Now().UTC()
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM rate_limits
+        WHERE reset_at < @before;
+    `, pgx.NamedArgs{"before": now.Add(-time.Hour)}); err != nil {
+		return 0, time.Time{}, fmt.Errorf("deleting ended rate limits: %w", err)
+	}
+	row := p.conn.QueryRow(ctx, `
+        INSERT INTO rate_limits ("key", "count", "reset_at")
+        VALUES (@key, 1, @reset_at)
+        ON CONFLICT ("key") DO UPDATE SET
+            "count" = CASE WHEN rate_limits.reset_at <= @now THEN 1 ELSE rate_limits.count + 1 END,
+            "reset_at" = CASE WHEN rate_limits.reset_at <= @now THEN EXCLUDED.reset_at ELSE rate_limits.reset_at END
+        RETURNING count, reset_at;
+    `, pgx.NamedArgs{
+		"key":      key,
+		"now":      now,
+		"reset_at": now.Add(window),
+	})
+	var count int
+	var resetAt time.Time
+	if err := row.Scan(&count, &resetAt); err != nil {
+		return 0, time.Time{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return count, resetAt, nil
+}
+
+func (p *rateLimitPostgreSQL) Get(ctx context.Context, key string) (int, time.Time, error) {
+	row := p.conn.QueryRow(ctx, `
+        SELECT count, reset_at
+        FROM rate_limits
+        WHERE key = @key AND reset_at > @now;
+    `, pgx.NamedArgs{
+		"key": key,
+		"now": time.Now().UTC(),
+	})
+	var count int
+	var resetAt time.Time
+	if err := row.Scan(&count, &resetAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, time.Time{}, nil
+		}
+		return 0, time.Time{}, fmt.Errorf("scanning query result: %w", err)
+	}
+	return count, resetAt, nil
+}
+
+func (p *rateLimitPostgreSQL) Delete(ctx context.Context, key string) error {
+	if _, err := p.conn.Exec(ctx, `
+        DELETE FROM rate_limits
+        WHERE key = $1;
+    `, key); err != nil {
+		return fmt.Errorf("deleting rate limit of %s: %w", key, err)
+	}
+	return nil
+}
+
+type rateLimitCounter struct {
+	count   int
+	resetAt time.Time
+}
+
+type rateLimitMemory struct {
+	mu       sync.Mutex
+	counters map[string]rateLimitCounter
+	sweptAt  time.Time
+}
+
+// NewRateLimitMemory counts in process memory, which only adds up with a
+// single process. With prefork every child counts on its own, so clients
+// get as many times the limit as there are CPUs at most.
+func NewRateLimitMemory() IRateLimitStorage {
+	return &rateLimitMemory{counters: map[string]rateLimitCounter{}}
+}
+
+func (m *rateLimitMemory) Increment(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	// sweeping at most once a minute keeps the cost off most hits
+	if now.Sub(m.sweptAt) > time.Minute {
+		for k, counter := range m.counters {
+			if !counter.resetAt.After(now) {
+				delete(m.counters, k)
+			}
+		}
+		m.sweptAt = now
+	}
+	counter, ok := m.counters[key]
+	if !ok || !counter.resetAt.After(now) {
+		counter = rateLimitCounter{resetAt: now.Add(window)}
+	}
+	counter.count++
+	m.counters[key] = counter
+	return counter.count, counter.resetAt, nil
+}
+
+func (m *rateLimitMemory) Get(ctx context.Context, key string) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counter, ok := m.counters[key]
+	if !ok || !counter.resetAt.After(time.Now()) {
+		return 0, time.Time{}, nil
+	}
+	return counter.count, counter.resetAt, nil
+}
+
+func (m *rateLimitMemory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.counters, key)
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefixRateLimit keeps rate limit keys apart from anything else sharing
+// the Redis database.
+const keyPrefixRateLimit = "token-service:ratelimit:"
+
+// incrementScript counts a hit and starts the window with the first one,
+// atomically so no key is left without an expiry.
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+    redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+type rateLimitRedis struct {
+	client *redis.Client
+}
+
+func NewRateLimitRedis(client *redis.Client) IRateLimitStorage {
+	return &rateLimitRedis{client}
+}
+
+func (r *rateLimitRedis) Increment(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+) (int, time.Time, error) {
+	result, err := incrementScript.Run(
+		ctx,
+		r.client,
+		[]string{keyPrefixRateLimit + key},
+		window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("incrementing rate limit of %s: %w", key, err)
+	}
+	return int(result[0]), time.Now().Add(time.Duration(result[1]) * time.Millisecond), nil
+}
+
+func (r *rateLimitRedis) Get(ctx context.Context, key string) (int, time.Time, error) {
+	pipe := r.client.Pipeline()
+	get := pipe.Get(ctx, keyPrefixRateLimit+key)
+	ttl := pipe.PTTL(ctx, keyPrefixRateLimit+key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, time.Time{}, fmt.Errorf("getting rate limit of %s: %w", key, err)
+	}
+	count, err := get.Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, time.Time{}, nil
+		}
+		return 0, time.Time{}, fmt.Errorf("parsing rate limit of %s: %w", key, err)
+	}
+	return count, time.Now().Add(ttl.Val()), nil
+}
+
+func (r *rateLimitRedis) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, keyPrefixRateLimit+key).Err(); err != nil {
+		return fmt.Errorf("deleting rate limit of %s: %w", key, err)
+	}
+	return nil
+}
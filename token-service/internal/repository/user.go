@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+type userHTTP struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// NewUserHTTP resolves users through user-service's internal API, baseURL
+// being the prefix its /v1/users routes are mounted under.
+func NewUserHTTP(client *http.Client, baseURL, apiKey string) IUserStorage {
+	return &userHTTP{client, baseURL, apiKey}
+}
+
+func (u *userHTTP) GetUserID(ctx context.Context, email string) (uint64, error) {
+	endpoint := fmt.Sprintf(
+		"%s/v1/users?%s",
+		u.baseURL,
+		url.Values{"email": {email}}.Encode(),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating user lookup request: %w", err)
+	}
+	req.Header.Set("Authorization", u.apiKey)
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("requesting user for email %s: %w", email, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, ErrNoRow
+	default:
+		return 0, fmt.Errorf("requesting user for email %s: unexpected status %d", email, resp.StatusCode)
+	}
+	user := new(struct {
+		ID uint64 `json:"id"`
+	})
+	if err := json.NewDecoder(resp.Body).Decode(user); err != nil {
+		return 0, fmt.Errorf("decoding user response: %w", err)
+	}
+	return user.ID, nil
+}
+
+func (u *userHTTP) GetUser(ctx context.Context, id uint64) (types.User, error) {
+	body, err := json.Marshal(map[string][]uint64{"ids": {id}})
+	if err != nil {
+		return types.User{}, fmt.Errorf("encoding user batch request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/v1/users:batchGet", u.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return types.User{}, fmt.Errorf("creating user batch request: %w", err)
+	}
+	req.Header.Set("Authorization", u.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return types.User{}, fmt.Errorf("requesting user for id %d: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return types.User{}, fmt.Errorf("requesting user for id %d: unexpected status %d", id, resp.StatusCode)
+	}
+	batch := new(struct {
+		Users []types.User `json:"users"`
+	})
+	if err := json.NewDecoder(resp.Body).Decode(batch); err != nil {
+		return types.User{}, fmt.Errorf("decoding user batch response: %w", err)
+	}
+	if len(batch.Users) == 0 {
+		return types.User{}, ErrNoRow
+	}
+	return batch.Users[0], nil
+}
+
+func (u *userHTTP) GetGroupNames(ctx context.Context, id uint64) ([]string, error) {
+	endpoint := fmt.Sprintf("%s/v1/users/%d/groups", u.baseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating user groups request: %w", err)
+	}
+	req.Header.Set("Authorization", u.apiKey)
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting groups for id %d: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting groups for id %d: unexpected status %d", id, resp.StatusCode)
+	}
+	groups := []struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return nil, fmt.Errorf("decoding groups response: %w", err)
+	}
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = group.Name
+	}
+	return names, nil
+}
+
+// SyncProfile hands the Google profile seen at login over to user-service,
+// which decides whether the user's full name follows it.
+func (u *userHTTP) SyncProfile(ctx context.Context, id uint64, identity *types.Identity) error {
+	body, err := json.Marshal(map[string]string{
+		"name":       identity.Name,
+		"givenName":  identity.GivenName,
+		"familyName": identity.FamilyName,
+		"picture":    identity.Picture,
+		"locale":     identity.Locale,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding google profile: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/v1/users/%d/google", u.baseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating profile sync request: %w", err)
+	}
+	req.Header.Set("Authorization", u.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("syncing profile for id %d: %w", id, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrNoRow
+	default:
+		return fmt.Errorf("syncing profile for id %d: unexpected status %d", id, resp.StatusCode)
+	}
+}
@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCode checks the SHA1 vectors of RFC 6238 appendix B, truncated to six
+// digits.
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, code := range tests {
+		actual, err := Code(secret, Step(time.Unix(unix, 0)))
+		assert.Nil(t, err)
+		assert.Equal(t, code, actual, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := NewSecret()
+	now := time.Now()
+	code, err := Code(secret, Step(now)-1)
+	assert.Nil(t, err)
+	step, ok := Validate(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+
+	code, err = Code(secret, Step(now)-2)
+	assert.Nil(t, err)
+	_, ok = Validate(secret, code, now)
+	assert.False(t, ok, "codes two steps old are expired")
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = Validate(strings.ToLower(secret), "abcdef", now)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Lab ICN", "admin@example.com", "JBSWY3DPEHPK3PXP")
+	assert.Equal(t, "otpauth://totp/Lab%20ICN:admin@example.com?algorithm=SHA1&digits=6&issuer=Lab+ICN&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// AuthState is what token-service remembers of an authorization request
+// between sending the user to the provider and their coming back.
+type AuthState struct {
+	State    string
+	Provider string
+	Nonce    string
+	Verifier string
+	// Redirect is the frontend path to land on afterwards
+	Redirect string
+	// Authorization is set when a relying party started the login, which
+	// then ends at its redirect URI instead of the frontend
+	Authorization *AuthorizationRequest
+	ExpiresAt     time.Time
+}
+
+type CallbackParams struct {
+	State string
+	Code  string
+	// Error is set by the provider instead of Code when the user did not
+	// authorize
+	Error string
+	// BoundState is the state the browser was given when it started
+	BoundState string
+	Device     Device
+}
+
+// AuthRedirect is where to send the user. State is set when that is a
+// provider, for the browser to be bound to.
+type AuthRedirect struct {
+	Location string
+	State    string
+}
+
+// AuthResult is the outcome of a callback. Error holds the reason of a
+// refused login, for the frontend to show, in which case there are no
+// tokens. MFAToken replaces them for users with a second factor. Location
+// is set instead for logins a relying party asked for, unless the user has
+// to answer an MFA challenge first, which Authorize marks.
+type AuthResult struct {
+	Redirect     string
+	Location     string
+	RefreshToken string
+	AccessToken  string
+	MFAToken     string
+	Authorize    bool
+	Error        string
+}
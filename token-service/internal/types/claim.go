@@ -2,10 +2,21 @@ package types
 
 import "github.com/golang-jwt/jwt/v5"
 
-type GoogleClaims struct {
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
-	FirstName     string `json:"given_name"`
-	LastName      string `json:"family_name"`
+type AccessClaims struct {
+	Groups []string `json:"groups,omitempty"`
+	// Session is the ID of the session the token was issued for
+	Session uint64 `json:"sid,omitempty"`
+	// Act names the admin behind an impersonation token
+	Act *Actor `json:"act,omitempty"`
+	// AMR are the methods the user authenticated with beyond their identity
+	// provider, the AMR constants
+	AMR []string `json:"amr,omitempty"`
+	// Scope limits tokens exchanged for an audience, space separated
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
+
+// Actor is the act claim of RFC 8693, the party acting as the subject.
+type Actor struct {
+	Subject string `json:"sub"`
+}
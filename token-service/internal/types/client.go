@@ -0,0 +1,52 @@
+package types
+
+import "github.com/golang-jwt/jwt/v5"
+
+// Client is a machine caller registered for the client credentials grant.
+type Client struct {
+	ID         string
+	Name       string
+	SecretHash string
+	Scopes     []string
+	// RedirectURIs are where a relying party may receive authorization
+	// codes, matched exactly
+	RedirectURIs []string
+}
+
+// TokenParams are the parameters of the token endpoint, which grants use
+// depending on their type.
+type TokenParams struct {
+	ClientID     string
+	ClientSecret string
+	// Scope is space separated, every allowed scope when empty
+	Scope        string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	// SubjectToken is the access token a token exchange trades for one
+	// limited to Audience, of SubjectTokenType
+	SubjectToken       string
+	SubjectTokenType   string
+	RequestedTokenType string
+	Audience           string
+}
+
+// ClientClaims are carried by access tokens issued to clients. With client
+// credentials the client acts on its own behalf and is the subject, with an
+// authorization code the subject is the user who logged in.
+type ClientClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// OAuthToken is a successful response of the OAuth token endpoint.
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	IDToken     string `json:"id_token,omitempty"`
+	// IssuedTokenType is only answered to token exchanges
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+}
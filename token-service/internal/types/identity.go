@@ -0,0 +1,27 @@
+package types
+
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+	ProviderOIDC   = "oidc"
+	// ProviderEmail vouches for emails by mailing a login link to them
+	ProviderEmail = "email"
+)
+
+// Identity is who an identity provider vouches for, whatever kind of
+// credential it was proven with.
+type Identity struct {
+	// Provider is the kind of provider, one of the Provider constants
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+	Picture       string
+	Locale        string
+	// HostedDomain is the Google Workspace domain managing the account,
+	// empty for consumer accounts and other providers
+	HostedDomain string
+}
@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// Impersonation is the audit record of an admin acting as another user.
+type Impersonation struct {
+	AdminID   uint64
+	UserID    uint64
+	Reason    string
+	IP        string
+	ExpiresAt time.Time
+}
+
+type ImpersonateParams struct {
+	UserID uint64 `json:"userId" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
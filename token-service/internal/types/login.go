@@ -0,0 +1,47 @@
+package types
+
+import "time"
+
+// LoginAttempt is one login, successful or not. UserID is zero when the
+// attempt never got as far as a user, and Reason is that of the refusal.
+type LoginAttempt struct {
+	ID             uint64    `json:"id"`
+	UserID         uint64    `json:"-"`
+	Email          string    `json:"email,omitempty"`
+	Provider       string    `json:"provider"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"userAgent"`
+	Device         string    `json:"device"`
+	Country        string    `json:"country,omitempty"`
+	Success        bool      `json:"success"`
+	Reason         string    `json:"reason,omitempty"`
+	NewDevice      bool      `json:"newDevice"`
+	UnusualCountry bool      `json:"unusualCountry"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// LoginFamiliarity is what earlier successful logins of a user have in
+// common with a new one.
+type LoginFamiliarity struct {
+	// Any is false for a first login, which nothing is unusual about
+	Any     bool
+	Device  bool
+	Country bool
+}
+
+const EventSuspiciousLogin = "suspiciousLogin"
+
+// LoginEvent announces a successful login from a new device or an unusual
+// country, for the user to be notified of.
+type LoginEvent struct {
+	Type           string    `json:"type"`
+	UserID         uint64    `json:"userId"`
+	Email          string    `json:"email"`
+	Provider       string    `json:"provider"`
+	IP             string    `json:"ip"`
+	Device         string    `json:"device"`
+	Country        string    `json:"country,omitempty"`
+	NewDevice      bool      `json:"newDevice"`
+	UnusualCountry bool      `json:"unusualCountry"`
+	Time           time.Time `json:"time"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// MagicLink is a one-time login link mailed to Email, of which only the
+// hash of the token is kept.
+type MagicLink struct {
+	TokenHash string
+	Email     string
+	ExpiresAt time.Time
+}
+
+type MagicLinkParams struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Mail is a plain text email.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
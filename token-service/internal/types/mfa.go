@@ -0,0 +1,55 @@
+package types
+
+import "time"
+
+// Authentication methods of the amr claim, from RFC 8176.
+const (
+	AMRMFA = "mfa"
+	AMROTP = "otp"
+	// AMRRecovery is not registered, logins with a recovery code are
+	// multi-factor all the same
+	AMRRecovery = "recovery"
+)
+
+// MFAFactor is the TOTP factor of a user. PendingSecret is one enrolled but
+// not yet confirmed with a code, which Secret only becomes once it is.
+type MFAFactor struct {
+	UserID        uint64
+	Secret        string
+	PendingSecret string
+	// LastStep is the time step of the latest code used, no code of it or
+	// an earlier one is taken again
+	LastStep    int64
+	ConfirmedAt *time.Time
+}
+
+// MFAChallenge stands between the first factor of a login and its tokens.
+type MFAChallenge struct {
+	TokenHash string
+	UserID    uint64
+	// Authorization is set when a relying party started the login, which
+	// gets an authorization code instead of tokens once it is answered
+	Authorization *AuthorizationRequest
+	Attempts      int
+	ExpiresAt     time.Time
+}
+
+// TokenPair is the outcome of a login, either tokens or, for users with a
+// second factor, the token of the challenge to answer for them.
+type TokenPair struct {
+	RefreshToken string
+	AccessToken  string
+	MFAToken     string
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth URI for authenticator apps to scan
+	URI string `json:"uri"`
+}
+
+type MFAParams struct {
+	Token string `json:"mfaToken" validate:"required"`
+	// Code is a TOTP code or one of the recovery codes
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthorizationRequest is what a relying party asked for at the
+// authorization endpoint, kept while the user logs in upstream.
+type AuthorizationRequest struct {
+	ClientID            string `json:"clientId"`
+	RedirectURI         string `json:"redirectUri"`
+	ResponseType        string `json:"responseType"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallenge       string `json:"codeChallenge"`
+	CodeChallengeMethod string `json:"codeChallengeMethod"`
+}
+
+// AuthorizationCode is handed to a relying party once the user logged in,
+// to be exchanged for tokens at the token endpoint.
+type AuthorizationCode struct {
+	Code        string
+	ClientID    string
+	UserID      uint64
+	RedirectURI string
+	Scope       string
+	Nonce       string
+	Challenge   string
+	AuthTime    time.Time
+	// AMR is how the user authenticated, for the ID token
+	AMR       []string
+	ExpiresAt time.Time
+}
+
+// User is what user-service knows of a user that relying parties may see.
+type User struct {
+	ID        uint64 `json:"id"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	Fullname  string `json:"fullname"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+// UserClaims are the standard claims about a user, released by scope.
+type UserClaims struct {
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     bool     `json:"email_verified,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Picture           string   `json:"picture,omitempty"`
+	Groups            []string `json:"groups,omitempty"`
+}
+
+// UserInfo is the response of the userinfo endpoint.
+type UserInfo struct {
+	Subject string `json:"sub"`
+	UserClaims
+}
+
+type IDClaims struct {
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	AMR      []string         `json:"amr,omitempty"`
+	UserClaims
+	jwt.RegisteredClaims
+}
+
+// OpenIDConfiguration is the discovery document of OpenID Connect
+// Discovery 1.0.
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// RateLimit is where a client stands against one of its limits.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Reset is when the window ends, or the lockout
+	Reset time.Time
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// Device is where a login or refresh request came from.
+type Device struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a refresh token handed out at a login, listed to its user
+// without the token itself.
+type Session struct {
+	ID     uint64 `json:"id"`
+	UserID uint64 `json:"userId"`
+	Token  string `json:"-"`
+	// Device is the browser and operating system read from UserAgent
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	// AMR are the methods of the login beyond the identity provider
+	AMR []string `json:"amr,omitempty"`
+	// Groups are those the last access token of the session carried, nil
+	// for sessions from before they were recorded
+	Groups []string `json:"-"`
+	// ExpiresAt is unknown for sessions from before they were tracked
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Current marks the session the listing access token belongs to
+	Current bool `json:"current"`
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+const (
+	defaultStateTTL = 10 * time.Minute
+	defaultCodeTTL  = time.Minute
+)
+
+type IAuthUsecase interface {
+	// Start begins an authorization code flow with the provider, returning
+	// where to send the user along with the state to bind to their browser.
+	Start(ctx context.Context, providerName, redirect string) (*types.AuthRedirect, error)
+	// StartAuthorization begins the same flow on behalf of a relying party,
+	// whose request is answered with an authorization code at the end.
+	StartAuthorization(
+		ctx context.Context,
+		providerName string,
+		request *types.AuthorizationRequest,
+	) (*types.AuthRedirect, error)
+	// Callback refuses states other than the one bound to the browser, so
+	// nobody can finish a login they started in someone else's browser.
+	Callback(
+		ctx context.Context,
+		providerName string,
+		params *types.CallbackParams,
+	) (*types.AuthResult, error)
+	// VerifyMFA answers the MFA challenge Callback gave a relying party
+	// login, sending the user back to it with an authorization code.
+	VerifyMFA(ctx context.Context, params *types.MFAParams) (*types.AuthResult, error)
+}
+
+type authUsecase struct {
+	tokens    ITokenUsecase
+	logins    ILoginUsecase
+	states    repository.IAuthStateStorage
+	codes     repository.IAuthorizationCodeStorage
+	providers map[string]provider.IProvider
+	cfg       *config.Config
+}
+
+func NewAuthUsecase(
+	tokens ITokenUsecase,
+	logins ILoginUsecase,
+	states repository.IAuthStateStorage,
+	codes repository.IAuthorizationCodeStorage,
+	providers map[string]provider.IProvider,
+	cfg *config.Config,
+) IAuthUsecase {
+	return &authUsecase{tokens, logins, states, codes, providers, cfg}
+}
+
+func (u *authUsecase) Start(ctx context.Context, providerName, redirect string) (*types.AuthRedirect, error) {
+	if redirect == "" {
+		redirect = "/"
+	}
+	// Only paths on the frontend, anything else would make this an open
+	// redirect.
+	if !strings.HasPrefix(redirect, "/") ||
+		strings.HasPrefix(redirect, "//") ||
+		strings.Contains(redirect, "\\") {
+		return nil, &Error{
+			Code:    http.StatusBadRequest,
+			Message: msgInvalidRedirect,
+		}
+	}
+	return u.start(ctx, providerName, redirect, nil)
+}
+
+func (u *authUsecase) StartAuthorization(
+	ctx context.Context,
+	providerName string,
+	request *types.AuthorizationRequest,
+) (*types.AuthRedirect, error) {
+	return u.start(ctx, providerName, "", request)
+}
+
+func (u *authUsecase) start(
+	ctx context.Context,
+	providerName, redirect string,
+	authorization *types.AuthorizationRequest,
+) (*types.AuthRedirect, error) {
+	idp, err := u.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	ttl := defaultStateTTL
+	if u.cfg.Auth.StateTTL > 0 {
+		ttl = time.Duration(u.cfg.Auth.StateTTL) * time.Minute
+	}
+	secrets := make([]string, 3)
+	for i := range secrets {
+		if secrets[i], err = randomString(); err != nil {
+			return nil, err
+		}
+	}
+	state := &types.AuthState{
+		State:         secrets[0],
+		Provider:      providerName,
+		Nonce:         secrets[1],
+		Verifier:      secrets[2],
+		Redirect:      redirect,
+		Authorization: authorization,
+		ExpiresAt:     time.Now().Add(ttl),
+	}
+	if err := u.states.CreateAuthState(ctx, state); err != nil {
+		return nil, fmt.Errorf("create auth state: %w", err)
+	}
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	authURL, err := idp.AuthCodeURL(
+		ctx,
+		u.callbackURL(providerName),
+		state.State,
+		state.Nonce,
+		base64.RawURLEncoding.EncodeToString(challenge[:]),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build %s authorization url: %w", providerName, err)
+	}
+	return &types.AuthRedirect{Location: authURL, State: state.State}, nil
+}
+
+func (u *authUsecase) Callback(
+	ctx context.Context,
+	providerName string,
+	params *types.CallbackParams,
+) (*types.AuthResult, error) {
+	idp, err := u.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if params.State == "" ||
+		subtle.ConstantTimeCompare([]byte(params.State), []byte(params.BoundState)) != 1 {
+		return nil, &Error{
+			Code:    http.StatusBadRequest,
+			Message: msgInvalidState,
+		}
+	}
+	state, err := u.states.ConsumeAuthState(ctx, params.State)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return nil, &Error{
+				Code:    http.StatusBadRequest,
+				Message: msgInvalidState,
+			}
+		}
+		return nil, fmt.Errorf("consume auth state: %w", err)
+	}
+	if state.Provider != providerName {
+		return nil, &Error{
+			Code:    http.StatusBadRequest,
+			Message: msgInvalidState,
+		}
+	}
+	result := &types.AuthResult{Redirect: state.Redirect}
+	if params.Error != "" {
+		result.Error = reasonAuthorizationDenied
+		return u.answer(ctx, state.Authorization, result, 0, nil)
+	}
+	identity, err := idp.Exchange(ctx, u.callbackURL(providerName), params.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, provider.ErrInvalidCredential) {
+			result.Error = reasonInvalidCredential
+			u.logins.Record(ctx, &types.LoginAttempt{
+				Provider:  providerName,
+				IP:        params.Device.IP,
+				UserAgent: params.Device.UserAgent,
+				Reason:    reasonInvalidCredential,
+			})
+			return u.answer(ctx, state.Authorization, result, 0, nil)
+		}
+		return nil, fmt.Errorf("exchange %s code: %w", providerName, err)
+	}
+	if state.Authorization != nil {
+		id, err := u.tokens.Authenticate(ctx, identity, &params.Device)
+		if err != nil {
+			reason, ok := refusal(err)
+			if !ok {
+				return nil, err
+			}
+			result.Error = reason
+			return u.answer(ctx, state.Authorization, result, 0, nil)
+		}
+		// Relying parties get no code before the second factor either.
+		token, err := u.tokens.Challenge(ctx, id, state.Authorization)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			result.MFAToken = token
+			result.Authorize = true
+			return result, nil
+		}
+		return u.answer(ctx, state.Authorization, result, id, nil)
+	}
+	tokens, err := u.tokens.Generate(ctx, identity, &params.Device)
+	if err != nil {
+		reason, ok := refusal(err)
+		if !ok {
+			return nil, err
+		}
+		result.Error = reason
+		return result, nil
+	}
+	result.RefreshToken = tokens.RefreshToken
+	result.AccessToken = tokens.AccessToken
+	result.MFAToken = tokens.MFAToken
+	return result, nil
+}
+
+func (u *authUsecase) VerifyMFA(ctx context.Context, params *types.MFAParams) (*types.AuthResult, error) {
+	challenge, amr, err := u.tokens.AnswerMFA(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return u.answer(ctx, challenge.Authorization, new(types.AuthResult), challenge.UserID, amr)
+}
+
+// answer sends the user back to the relying party of request, with an
+// authorization code for id, who authenticated with amr, or access_denied.
+// Logins the frontend started have no request and pass through.
+func (u *authUsecase) answer(
+	ctx context.Context,
+	request *types.AuthorizationRequest,
+	result *types.AuthResult,
+	id uint64,
+	amr []string,
+) (*types.AuthResult, error) {
+	if request == nil {
+		return result, nil
+	}
+	location, err := url.Parse(request.RedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("parse redirect uri of client %s: %w", request.ClientID, err)
+	}
+	query := location.Query()
+	if request.State != "" {
+		query.Set("state", request.State)
+	}
+	if result.Error != "" {
+		query.Set("error", "access_denied")
+		query.Set("error_description", result.Error)
+	} else {
+		ttl := defaultCodeTTL
+		if u.cfg.OIDC.CodeTTL > 0 {
+			ttl = time.Duration(u.cfg.OIDC.CodeTTL) * time.Minute
+		}
+		secret, err := randomString()
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		code := &types.AuthorizationCode{
+			Code:        secret,
+			ClientID:    request.ClientID,
+			UserID:      id,
+			RedirectURI: request.RedirectURI,
+			Scope:       request.Scope,
+			Nonce:       request.Nonce,
+			Challenge:   request.CodeChallenge,
+			AuthTime:    now,
+			AMR:         amr,
+			ExpiresAt:   now.Add(ttl),
+		}
+		if err := u.codes.CreateAuthorizationCode(ctx, code); err != nil {
+			return nil, fmt.Errorf("create authorization code: %w", err)
+		}
+		query.Set("code", code.Code)
+	}
+	location.RawQuery = query.Encode()
+	result.Location = location.String()
+	return result, nil
+}
+
+// refusal is the reason of the refused login err carries, if it is one.
+func refusal(err error) (string, bool) {
+	uscErr := new(Error)
+	if errors.As(err, &uscErr) && len(uscErr.Errors) > 0 {
+		return uscErr.Errors[0].Reason, true
+	}
+	return "", false
+}
+
+func (u *authUsecase) provider(name string) (provider.IAuthCodeProvider, error) {
+	idp, ok := u.providers[name].(provider.IAuthCodeProvider)
+	if !ok {
+		return nil, &Error{
+			Code:    http.StatusNotFound,
+			Message: msgUnknownProvider,
+		}
+	}
+	return idp, nil
+}
+
+func (u *authUsecase) callbackURL(providerName string) string {
+	return fmt.Sprintf("%s/v1/auth/%s/callback", strings.TrimSuffix(u.cfg.Auth.BaseURL, "/"), providerName)
+}
+
+// randomString is 256 bits of randomness, enough for states, nonces and
+// PKCE verifiers alike.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubIssuer is an OpenID Connect issuer that authorizes anyone as
+// test@example.com, checking the PKCE verifier on exchange.
+func newStubIssuer(t *testing.T) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	var (
+		mu     sync.Mutex
+		issued = map[string]url.Values{}
+	)
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"jwks_uri":               srv.URL + "/jwks",
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": "stub",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		issued["code"] = r.URL.Query()
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		authorize, ok := issued[r.Form.Get("code")]
+		delete(issued, r.Form.Get("code"))
+		mu.Unlock()
+		challenge := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if !ok || authorize.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            srv.URL,
+			"aud":            authorize.Get("client_id"),
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"nonce":          authorize.Get("nonce"),
+			"email":          "test@example.com",
+			"email_verified": true,
+		})
+		token.Header["kid"] = "stub"
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	return srv
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Auth.BaseURL = "https://example.com/backend"
+	u := usecase.NewAuthUsecase(
+		newUsecaseWith(cfg),
+		newLogins(cfg),
+		repository.NewAuthStateFake(),
+		repository.NewAuthorizationCodeFake(),
+		map[string]provider.IProvider{
+			"sso": provider.NewOIDC(issuer.Client(), issuer.URL, "client", "secret", &log),
+		},
+		cfg,
+	)
+
+	redirect, err := u.Start(ctx, "sso", "/dashboard")
+	assert.Nil(t, err)
+	resp, err := issuer.Client().Get(redirect.Location)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	query, err := url.Parse(redirect.Location)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/backend/v1/auth/sso/callback", query.Query().Get("redirect_uri"))
+	state := query.Query().Get("state")
+	assert.Equal(t, state, redirect.State)
+
+	uscErr := new(usecase.Error)
+	for _, bound := range []string{"", "another-state"} {
+		_, err = u.Callback(ctx, "sso", &types.CallbackParams{State: state, Code: "code", BoundState: bound})
+		assert.True(t, errors.As(err, &uscErr), "logins finish in the browser they started in")
+		assert.Equal(t, http.StatusBadRequest, uscErr.Code)
+	}
+
+	result, err := u.Callback(ctx, "sso", &types.CallbackParams{State: state, Code: "code", BoundState: state})
+	assert.Nil(t, err)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "/dashboard", result.Redirect)
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.NotEmpty(t, result.AccessToken)
+
+	_, err = u.Callback(ctx, "sso", &types.CallbackParams{State: state, Code: "code", BoundState: state})
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusBadRequest, uscErr.Code)
+}
+
+func TestAuthStartOpenRedirect(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	u := usecase.NewAuthUsecase(
+		newUsecaseWith(cfg),
+		newLogins(cfg),
+		repository.NewAuthStateFake(),
+		repository.NewAuthorizationCodeFake(),
+		map[string]provider.IProvider{
+			"sso": provider.NewOIDC(http.DefaultClient, "https://sso.example.com", "client", "secret", &log),
+		},
+		cfg,
+	)
+	for _, redirect := range []string{"https://evil.example.com", "//evil.example.com", "/\\evil.example.com"} {
+		_, err := u.Start(ctx, "sso", redirect)
+		uscErr := new(usecase.Error)
+		assert.True(t, errors.As(err, &uscErr), redirect)
+		assert.Equal(t, http.StatusBadRequest, uscErr.Code, redirect)
+	}
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	GrantClientCredentials = "client_credentials"
+	defaultClientTTL       = 5 * time.Minute
+	// clientAudience is the aud of client credentials tokens, as the scopes
+	// clients are granted are those of user-service
+	clientAudience = "user-service"
+)
+
+type IClientUsecase interface {
+	// Register creates a client allowed the given scopes and redirect URIs,
+	// returning its ID and the secret, which is only ever known at this
+	// point.
+	Register(ctx context.Context, name string, scopes, redirectURIs []string) (string, string, error)
+	List(ctx context.Context) ([]types.Client, error)
+	Delete(ctx context.Context, id string) error
+	// Grant serves the token endpoint for grantType.
+	Grant(ctx context.Context, grantType string, params *types.TokenParams) (*types.OAuthToken, error)
+}
+
+type clientUsecase struct {
+	store repository.IClientStorage
+	cfg   *config.Config
+}
+
+func NewClientUsecase(store repository.IClientStorage, cfg *config.Config) IClientUsecase {
+	return &clientUsecase{store, cfg}
+}
+
+func (u *clientUsecase) Register(
+	ctx context.Context,
+	name string,
+	scopes, redirectURIs []string,
+) (string, string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("read random bytes: %w", err)
+	}
+	secret, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("hash client secret: %w", err)
+	}
+	client := &types.Client{
+		ID:           hex.EncodeToString(id),
+		Name:         name,
+		SecretHash:   string(hash),
+		Scopes:       scopes,
+		RedirectURIs: redirectURIs,
+	}
+	if err := u.store.CreateClient(ctx, client); err != nil {
+		return "", "", fmt.Errorf("create client %s: %w", name, err)
+	}
+	return client.ID, secret, nil
+}
+
+func (u *clientUsecase) List(ctx context.Context) ([]types.Client, error) {
+	return u.store.ListClients(ctx)
+}
+
+func (u *clientUsecase) Delete(ctx context.Context, id string) error {
+	if err := u.store.DeleteClient(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return &Error{
+				Code:    http.StatusNotFound,
+				Message: msgClientNotFound,
+			}
+		}
+		return fmt.Errorf("delete client %s: %w", id, err)
+	}
+	return nil
+}
+
+func (u *clientUsecase) Grant(
+	ctx context.Context,
+	grantType string,
+	params *types.TokenParams,
+) (*types.OAuthToken, error) {
+	if grantType != GrantClientCredentials {
+		return nil, &OAuthError{
+			Code:   http.StatusBadRequest,
+			Reason: oauthUnsupportedGrantType,
+		}
+	}
+	client, err := authenticateClient(ctx, u.store, params.ClientID, params.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	scopes := client.Scopes
+	if params.Scope != "" {
+		scopes = strings.Fields(params.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(client.Scopes, scope) {
+				return nil, &OAuthError{
+					Code:        http.StatusBadRequest,
+					Reason:      oauthInvalidScope,
+					Description: fmt.Sprintf("scope %s is not allowed for this client", scope),
+				}
+			}
+		}
+	}
+	ttl := defaultClientTTL
+	if u.cfg.OAuth.ClientTTL > 0 {
+		ttl = time.Duration(u.cfg.OAuth.ClientTTL) * time.Minute
+	}
+	now := time.Now().UTC()
+	scope := strings.Join(scopes, " ")
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodHS512,
+		types.ClientClaims{
+			ClientID: client.ID,
+			Scope:    scope,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   client.ID,
+				Audience:  jwt.ClaimStrings{clientAudience},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+		},
+	)
+	accessToken, err := token.SignedString([]byte(u.cfg.JWT.Key))
+	if err != nil {
+		return nil, fmt.Errorf("signing client access token: %w", err)
+	}
+	return &types.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClient refuses unknown clients and wrong secrets alike, so
+// callers cannot probe for client IDs.
+func authenticateClient(
+	ctx context.Context,
+	store repository.IClientStorage,
+	id, secret string,
+) (types.Client, error) {
+	invalid := &OAuthError{
+		Code:   http.StatusUnauthorized,
+		Reason: oauthInvalidClient,
+	}
+	if id == "" || secret == "" {
+		return types.Client{}, invalid
+	}
+	client, err := store.GetClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return types.Client{}, invalid
+		}
+		return types.Client{}, fmt.Errorf("fetch client %s: %w", id, err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(client.SecretHash), []byte(secret)); err != nil {
+		return types.Client{}, invalid
+	}
+	return client, nil
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	u := usecase.NewClientUsecase(repository.NewClientFake(), newConfig())
+	id, secret, err := u.Register(ctx, "seeder", []string{"users:read", "users:write"}, nil)
+	assert.Nil(t, err)
+
+	token, err := u.Grant(ctx, usecase.GrantClientCredentials, &types.TokenParams{
+		ClientID:     id,
+		ClientSecret: secret,
+		Scope:        "users:read",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, "users:read", token.Scope)
+	claims := new(types.ClientClaims)
+	_, err = jwt.ParseWithClaims(token.AccessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, id, claims.ClientID)
+	assert.Equal(t, "users:read", claims.Scope)
+	assert.Equal(t, jwt.ClaimStrings{"user-service"}, claims.Audience)
+
+	token, err = u.Grant(ctx, usecase.GrantClientCredentials, &types.TokenParams{
+		ClientID:     id,
+		ClientSecret: secret,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "users:read users:write", token.Scope)
+}
+
+func TestClientCredentialsRefused(t *testing.T) {
+	ctx := context.Background()
+	u := usecase.NewClientUsecase(repository.NewClientFake(), newConfig())
+	id, secret, err := u.Register(ctx, "cron", []string{"users:read"}, nil)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		grantType string
+		params    types.TokenParams
+		code      int
+		reason    string
+	}{
+		{"wrong secret", usecase.GrantClientCredentials, types.TokenParams{ClientID: id, ClientSecret: "wrong"}, http.StatusUnauthorized, "invalid_client"},
+		{"unknown client", usecase.GrantClientCredentials, types.TokenParams{ClientID: "unknown", ClientSecret: secret}, http.StatusUnauthorized, "invalid_client"},
+		{"scope not allowed", usecase.GrantClientCredentials, types.TokenParams{ClientID: id, ClientSecret: secret, Scope: "users:write"}, http.StatusBadRequest, "invalid_scope"},
+		{"unsupported grant", "password", types.TokenParams{ClientID: id, ClientSecret: secret}, http.StatusBadRequest, "unsupported_grant_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Grant(ctx, tt.grantType, &tt.params)
+			oauthErr := new(usecase.OAuthError)
+			assert.True(t, errors.As(err, &oauthErr))
+			assert.Equal(t, tt.code, oauthErr.Code)
+			assert.Equal(t, tt.reason, oauthErr.Reason)
+		})
+	}
+}
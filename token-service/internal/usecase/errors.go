@@ -17,6 +17,65 @@ func (e Error) Error() string {
 	return e.Message
 }
 
+// OAuthError is an error of the OAuth token endpoint, shaped the way
+// RFC 6749 section 5.2 requires instead of like Error.
+type OAuthError struct {
+	Code        int    `json:"-"`
+	Reason      string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Err         error  `json:"-"`
+}
+
+func (e OAuthError) Error() string {
+	return e.Reason
+}
+
+// Reasons of OAuthError from RFC 6749.
+const (
+	oauthInvalidRequest       = "invalid_request"
+	oauthInvalidClient        = "invalid_client"
+	oauthInvalidGrant         = "invalid_grant"
+	oauthInvalidScope         = "invalid_scope"
+	oauthUnsupportedGrantType = "unsupported_grant_type"
+	// from RFC 8693
+	oauthInvalidTarget = "invalid_target"
+)
+
+const (
+	msgUserNotRegistered      = "user is not registered"
+	msgEmailUnverified        = "email is not verified by the identity provider"
+	msgHostedDomainNotAllowed = "account is not managed by an allowed organization"
+	msgDomainDenied           = "email domain is not allowed to log in"
+	msgProviderNotAllowed     = "email domain must log in with another provider"
+	msgHostedDomainRequired   = "email domain must log in with its organization's Google account"
+	msgUnknownProvider        = "identity provider not configured for this login flow"
+	msgInvalidRedirect        = "redirect must be a path on the frontend"
+	msgInvalidState           = "authorization state unknown or expired"
+	msgClientNotFound         = "oauth client not found"
+	msgUnknownClient          = "client_id is not a registered client"
+	msgInvalidRedirectURI     = "redirect_uri is not registered for the client"
+	msgInvalidAccessToken     = "access token invalid or not issued for userinfo"
+	msgNotAdmin               = "only admins may do this"
+	msgImpersonateAdmin       = "admins cannot be impersonated"
+	msgSessionNotFound        = "session not found"
+	msgRateLimited            = "too many requests, retry later"
+	msgLockedOut              = "too many failed attempts, retry later"
+	msgInvalidMFAToken        = "mfa token unknown or expired, log in again"
+	msgInvalidMFACode         = "code is wrong or already used"
+	msgMFARequired            = "requires a login with two-factor authentication"
+	msgNoPendingMFA           = "no two-factor authentication enrollment to confirm"
+	msgMFAReenrolled          = "two-factor authentication was enrolled again meanwhile"
+	msgInvalidMagicLink       = "login link unknown, used or expired"
+)
+
+// Reasons of refused logins, stable for the frontend to match on.
 const (
-	msgUserNotRegistered = "user is not registered"
+	reasonEmailUnverified        = "emailUnverified"
+	reasonHostedDomainNotAllowed = "hostedDomainNotAllowed"
+	reasonDomainDenied           = "domainDenied"
+	reasonProviderNotAllowed     = "providerNotAllowed"
+	reasonHostedDomainRequired   = "hostedDomainRequired"
+	reasonUserNotRegistered      = "userNotRegistered"
+	reasonAuthorizationDenied    = "authorizationDenied"
+	reasonInvalidCredential      = "invalidCredential"
 )
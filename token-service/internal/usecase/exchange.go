@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	GrantTokenExchange   = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	defaultExchangeTTL   = 5 * time.Minute
+)
+
+// subjectClaims are those of access tokens handed to ExchangeToken, which
+// client tokens carry a client_id among.
+type subjectClaims struct {
+	ClientID string `json:"client_id"`
+	types.AccessClaims
+}
+
+func (u *oidcUsecase) ExchangeToken(ctx context.Context, params *types.TokenParams) (*types.OAuthToken, error) {
+	invalid := func(description string) error {
+		return &OAuthError{
+			Code:        http.StatusBadRequest,
+			Reason:      oauthInvalidRequest,
+			Description: description,
+		}
+	}
+	if params.SubjectToken == "" || params.SubjectTokenType != TokenTypeAccessToken {
+		return nil, invalid("subject_token must be an access token")
+	}
+	if params.RequestedTokenType != "" && params.RequestedTokenType != TokenTypeAccessToken {
+		return nil, invalid("only access tokens are issued")
+	}
+	claims := new(subjectClaims)
+	if _, err := jwt.ParseWithClaims(params.SubjectToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(u.cfg.JWT.Key), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Name})); err != nil {
+		return nil, invalid("subject_token is invalid or expired")
+	}
+	if claims.ClientID != "" {
+		return nil, invalid("subject_token is not of a user")
+	}
+	// Exchanged tokens are only ever narrowed from a full one, so their
+	// scopes cannot be traded for those of another audience.
+	if len(claims.Audience) > 0 {
+		return nil, invalid("subject_token was already exchanged")
+	}
+	// Refresh tokens are signed alike but name no session, nor do
+	// impersonation tokens. The session is looked up so none revoked
+	// lives on in the tokens exchanged for it.
+	if claims.Session == 0 {
+		return nil, invalid("subject_token is not an access token of a session")
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, invalid("subject_token is not of a user")
+	}
+	sessions, err := u.tokens.ListSessions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching sessions of %d: %w", id, err)
+	}
+	if !slices.ContainsFunc(sessions, func(s types.Session) bool { return s.ID == claims.Session }) {
+		return nil, invalid("subject_token is of a revoked session")
+	}
+	var (
+		audience string
+		allowed  []string
+	)
+	for _, a := range u.cfg.OAuth.Audiences {
+		if params.Audience != "" && a.Name == params.Audience {
+			audience, allowed = a.Name, a.Scopes
+		}
+	}
+	if audience == "" {
+		return nil, &OAuthError{
+			Code:        http.StatusBadRequest,
+			Reason:      oauthInvalidTarget,
+			Description: "audience is not one tokens are exchanged for",
+		}
+	}
+	scopes := allowed
+	if params.Scope != "" {
+		scopes = strings.Fields(params.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(allowed, scope) {
+				return nil, &OAuthError{
+					Code:        http.StatusBadRequest,
+					Reason:      oauthInvalidScope,
+					Description: fmt.Sprintf("scope %s is not allowed for audience %s", scope, audience),
+				}
+			}
+		}
+	}
+	now := time.Now().UTC()
+	ttl := defaultExchangeTTL
+	if u.cfg.OAuth.ExchangeTTL > 0 {
+		ttl = time.Duration(u.cfg.OAuth.ExchangeTTL) * time.Minute
+	}
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Sub(now) < ttl {
+		ttl = claims.ExpiresAt.Sub(now).Truncate(time.Second)
+	}
+	scope := strings.Join(scopes, " ")
+	// Groups and amr stay behind, the audience is told only what its
+	// scopes allow. It checks the signature against the published keys,
+	// never holding the key full tokens are signed with.
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodRS256,
+		types.AccessClaims{
+			Session: claims.Session,
+			Scope:   scope,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    u.issuer(),
+				Subject:   claims.Subject,
+				Audience:  jwt.ClaimStrings{audience},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+		},
+	)
+	token.Header["kid"] = u.kid
+	accessToken, err := token.SignedString(u.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing exchanged access token: %w", err)
+	}
+	return &types.OAuthToken{
+		AccessToken:     accessToken,
+		IssuedTokenType: TokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int(ttl.Seconds()),
+		Scope:           scope,
+	}, nil
+}
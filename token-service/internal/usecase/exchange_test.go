@@ -0,0 +1,125 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenExchange(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	cfg.OAuth.Audiences = append(cfg.OAuth.Audiences, struct {
+		Name   string
+		Scopes []string
+	}{"calendar", []string{"events:read", "events:write"}})
+	cfg.JWT.AccessTTL = 60
+	cfg.Auth.BaseURL = "https://example.com/backend"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	log := zerolog.Nop()
+	store := repository.NewTokenFake()
+	tokens := usecase.NewTokenUsecase(store, newUsers(), newLogins(cfg), repository.NewMFAFake(), cfg, &log)
+	u := usecase.NewOIDCUsecase(
+		nil,
+		repository.NewClientFake(),
+		repository.NewAuthorizationCodeFake(),
+		newUsers(),
+		store,
+		key,
+		cfg,
+	)
+	pair, err := tokens.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+
+	token, err := u.ExchangeToken(ctx, &types.TokenParams{
+		SubjectToken:     pair.AccessToken,
+		SubjectTokenType: usecase.TokenTypeAccessToken,
+		Audience:         "calendar",
+		Scope:            "events:read",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, usecase.TokenTypeAccessToken, token.IssuedTokenType)
+	assert.Equal(t, "events:read", token.Scope)
+	assert.Equal(t, 300, token.ExpiresIn, "exchanged tokens live five minutes by default")
+	published, err := u.Keys()
+	assert.Nil(t, err)
+	keys, err := jwks.Parse(published)
+	assert.Nil(t, err)
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(token.AccessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys[kid], nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name}))
+	assert.Nil(t, err, "exchanged tokens are signed with the published key")
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "https://example.com/backend", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"calendar"}, claims.Audience)
+	assert.Equal(t, "events:read", claims.Scope)
+	assert.Empty(t, claims.Groups, "groups are not told to the audience")
+	assert.Empty(t, claims.AMR, "nor how the user logged in")
+
+	cfg.OAuth.ExchangeTTL = 120
+	token, err = u.ExchangeToken(ctx, &types.TokenParams{
+		SubjectToken:     pair.AccessToken,
+		SubjectTokenType: usecase.TokenTypeAccessToken,
+		Audience:         "calendar",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "events:read events:write", token.Scope)
+	assert.LessOrEqual(t, token.ExpiresIn, 3600, "exchanged tokens never outlive the subject token")
+
+	clientToken, err := jwt.NewWithClaims(jwt.SigningMethodHS512, types.ClientClaims{
+		ClientID: "seeder",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "seeder",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		params types.TokenParams
+		reason string
+	}{
+		{"missing token type", types.TokenParams{SubjectToken: pair.AccessToken, Audience: "calendar"}, "invalid_request"},
+		{"refresh token type", types.TokenParams{SubjectToken: pair.AccessToken, SubjectTokenType: "urn:ietf:params:oauth:token-type:refresh_token", Audience: "calendar"}, "invalid_request"},
+		{"forged token", types.TokenParams{SubjectToken: "forged", SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "calendar"}, "invalid_request"},
+		{"refresh token", types.TokenParams{SubjectToken: pair.RefreshToken, SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "calendar"}, "invalid_request"},
+		{"client token", types.TokenParams{SubjectToken: clientToken, SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "calendar"}, "invalid_request"},
+		{"exchanged token", types.TokenParams{SubjectToken: token.AccessToken, SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "calendar"}, "invalid_request"},
+		{"unknown audience", types.TokenParams{SubjectToken: pair.AccessToken, SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "mail"}, "invalid_target"},
+		{"missing audience", types.TokenParams{SubjectToken: pair.AccessToken, SubjectTokenType: usecase.TokenTypeAccessToken}, "invalid_target"},
+		{"wider scope", types.TokenParams{SubjectToken: pair.AccessToken, SubjectTokenType: usecase.TokenTypeAccessToken, Audience: "calendar", Scope: "events:delete"}, "invalid_scope"},
+	}
+	for _, tc := range tests {
+		_, err := u.ExchangeToken(ctx, &tc.params)
+		oauthErr := new(usecase.OAuthError)
+		assert.True(t, errors.As(err, &oauthErr), tc.name)
+		assert.Equal(t, http.StatusBadRequest, oauthErr.Code, tc.name)
+		assert.Equal(t, tc.reason, oauthErr.Reason, tc.name)
+	}
+
+	assert.Nil(t, tokens.Invalidate(ctx, 1, 0))
+	_, err = u.ExchangeToken(ctx, &types.TokenParams{
+		SubjectToken:     pair.AccessToken,
+		SubjectTokenType: usecase.TokenTypeAccessToken,
+		Audience:         "calendar",
+	})
+	oauthErr := new(usecase.OAuthError)
+	assert.True(t, errors.As(err, &oauthErr), "access tokens of revoked sessions are not exchanged")
+	assert.Equal(t, "invalid_request", oauthErr.Reason)
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// Kinds of clients limits apply to.
+const (
+	LimitIP      = "ip"
+	LimitAccount = "account"
+)
+
+const (
+	defaultLimitWindow = time.Minute
+	defaultLockout     = 15 * time.Minute
+)
+
+type ILimitUsecase interface {
+	// Allow counts a request of subject, an address or account by kind,
+	// refusing it with 429 once subject is over its limit or locked out.
+	// The limit is nil when there is none.
+	Allow(ctx context.Context, kind, subject string) (*types.RateLimit, error)
+	// Fail counts a failed request of subject, locking it out after too
+	// many.
+	Fail(ctx context.Context, kind, subject string)
+}
+
+type limitUsecase struct {
+	store repository.IRateLimitStorage
+	cfg   *config.Config
+	log   *zerolog.Logger
+}
+
+// NewLimitUsecase lets requests through when the store fails, logging it,
+// rather than refusing every login while it is down.
+func NewLimitUsecase(
+	store repository.IRateLimitStorage,
+	cfg *config.Config,
+	log *zerolog.Logger,
+) ILimitUsecase {
+	return &limitUsecase{store, cfg, log}
+}
+
+func (u *limitUsecase) Allow(ctx context.Context, kind, subject string) (*types.RateLimit, error) {
+	key := kind + ":" + subject
+	allowed := u.cfg.RateLimit.IP
+	if kind == LimitAccount {
+		allowed = u.cfg.RateLimit.Account
+	}
+	if u.cfg.RateLimit.Failures > 0 {
+		locked, until, err := u.store.Get(ctx, "lockout:"+key)
+		if err != nil {
+			u.log.Error().Err(err).Str("key", key).Msg("fetching lockout")
+		} else if locked > 0 {
+			return &types.RateLimit{Limit: allowed, Reset: until}, &Error{
+				Code:    http.StatusTooManyRequests,
+				Message: msgLockedOut,
+			}
+		}
+	}
+	if allowed <= 0 {
+		return nil, nil
+	}
+	window := defaultLimitWindow
+	if u.cfg.RateLimit.Window > 0 {
+		window = time.Duration(u.cfg.RateLimit.Window) * time.Second
+	}
+	count, reset, err := u.store.Increment(ctx, "requests:"+key, window)
+	if err != nil {
+		u.log.Error().Err(err).Str("key", key).Msg("counting request")
+		return nil, nil
+	}
+	limit := &types.RateLimit{
+		Limit:     allowed,
+		Remaining: max(allowed-count, 0),
+		Reset:     reset,
+	}
+	if count > allowed {
+		return limit, &Error{
+			Code:    http.StatusTooManyRequests,
+			Message: msgRateLimited,
+		}
+	}
+	return limit, nil
+}
+
+func (u *limitUsecase) Fail(ctx context.Context, kind, subject string) {
+	if u.cfg.RateLimit.Failures <= 0 {
+		return
+	}
+	key := kind + ":" + subject
+	lockout := defaultLockout
+	if u.cfg.RateLimit.Lockout > 0 {
+		lockout = time.Duration(u.cfg.RateLimit.Lockout) * time.Minute
+	}
+	failures, _, err := u.store.Increment(ctx, "failures:"+key, lockout)
+	if err != nil {
+		u.log.Error().Err(err).Str("key", key).Msg("counting failure")
+		return
+	}
+	if failures < u.cfg.RateLimit.Failures {
+		return
+	}
+	if _, _, err := u.store.Increment(ctx, "lockout:"+key, lockout); err != nil {
+		u.log.Error().Err(err).Str("key", key).Msg("locking out")
+		return
+	}
+	if err := u.store.Delete(ctx, "failures:"+key); err != nil {
+		u.log.Error().Err(err).Str("key", key).Msg("clearing failures")
+	}
+	u.log.Warn().Str("key", key).Int("failures", failures).Msg("locked out")
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/geoip"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultHistoryLimit = 50
+	reasonInternalError = "internalError"
+	// publishTimeout bounds publishing an event, which happens after the
+	// login was answered
+	publishTimeout = 10 * time.Second
+)
+
+type ILoginUsecase interface {
+	// Record stores a login attempt, flagging successful ones from a new
+	// device or an unusual country and publishing an event about them in
+	// the background. Failures are only logged, recording never holds a
+	// login up.
+	Record(ctx context.Context, attempt *types.LoginAttempt)
+	// History is the latest login attempts of user id.
+	History(ctx context.Context, id uint64) ([]types.LoginAttempt, error)
+}
+
+type loginUsecase struct {
+	store     repository.ILoginStorage
+	users     repository.IUserStorage
+	events    repository.IEventPublisher
+	countries *geoip.DB
+	cfg       *config.Config
+	log       *zerolog.Logger
+}
+
+// NewLoginUsecase locates attempts with countries, which may be nil to not
+// locate them at all.
+func NewLoginUsecase(
+	store repository.ILoginStorage,
+	users repository.IUserStorage,
+	events repository.IEventPublisher,
+	countries *geoip.DB,
+	cfg *config.Config,
+	log *zerolog.Logger,
+) ILoginUsecase {
+	return &loginUsecase{store, users, events, countries, cfg, log}
+}
+
+func (u *loginUsecase) Record(ctx context.Context, attempt *types.LoginAttempt) {
+	attempt.Device = describeDevice(attempt.UserAgent)
+	attempt.Country = u.countries.Country(attempt.IP)
+	if attempt.Success {
+		// Devices are told apart by their full user agent, the description
+		// is the same for everyone on a browser and system.
+		familiarity, err := u.store.GetLoginFamiliarity(ctx, attempt.UserID, attempt.UserAgent, attempt.Country)
+		if err != nil {
+			u.log.Error().Err(err).Uint64("id", attempt.UserID).Msg("fetching earlier logins")
+		} else if familiarity.Any {
+			// nothing is unusual about a first login, nor about a country
+			// nobody knows
+			attempt.NewDevice = !familiarity.Device
+			attempt.UnusualCountry = attempt.Country != "" && !familiarity.Country
+		}
+	}
+	if err := u.store.CreateLoginAttempt(ctx, attempt); err != nil {
+		u.log.Error().Err(err).Str("email", attempt.Email).Msg("recording login attempt")
+	}
+	if !attempt.NewDevice && !attempt.UnusualCountry {
+		return
+	}
+	event := &types.LoginEvent{
+		Type:           types.EventSuspiciousLogin,
+		UserID:         attempt.UserID,
+		Email:          attempt.Email,
+		Provider:       attempt.Provider,
+		IP:             attempt.IP,
+		Device:         attempt.Device,
+		Country:        attempt.Country,
+		NewDevice:      attempt.NewDevice,
+		UnusualCountry: attempt.UnusualCountry,
+		Time:           time.Now().UTC(),
+	}
+	// The login is answered meanwhile, and fiber recycles the request
+	// context for the next one once it is, so nothing of it may be kept.
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	go func() {
+		defer cancel()
+		if err := u.events.PublishLogin(ctx, event); err != nil {
+			u.log.Error().Err(err).Uint64("id", event.UserID).Msg("publishing suspicious login")
+		}
+	}()
+}
+
+func (u *loginUsecase) History(ctx context.Context, id uint64) ([]types.LoginAttempt, error) {
+	user, err := u.users.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultHistoryLimit
+	if u.cfg.History.Limit > 0 {
+		limit = u.cfg.History.Limit
+	}
+	return u.store.ListLoginAttempts(ctx, id, user.Email, limit)
+}
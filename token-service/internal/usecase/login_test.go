@@ -0,0 +1,129 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/geoip"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestLoginHistory(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	countries, err := geoip.Parse(strings.NewReader(`10.0.0.0,10.0.0.255,ID
+10.0.1.0,10.0.1.255,SG
+`))
+	assert.Nil(t, err)
+	events := repository.NewEventFake()
+	logins := usecase.NewLoginUsecase(repository.NewLoginFake(), newUsers(), events, countries, cfg, &log)
+	tokens := usecase.NewTokenUsecase(repository.NewTokenFake(), newUsers(), logins, repository.NewMFAFake(), cfg, &log)
+
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:133.0) Gecko/20100101 Firefox/133.0"
+	logins.Record(ctx, &types.LoginAttempt{
+		Provider: types.ProviderGoogle,
+		IP:       "10.0.0.1",
+		Reason:   "invalidCredential",
+	})
+	_, err = tokens.Generate(ctx, googleIdentity("test@example.com"), &types.Device{UserAgent: chrome, IP: "10.0.0.1"})
+	assert.Nil(t, err)
+	_, err = tokens.Generate(ctx, googleIdentity("test@example.com"), &types.Device{UserAgent: chrome, IP: "10.0.0.2"})
+	assert.Nil(t, err)
+	assert.Empty(t, events.PublishedLogins(), "first and familiar logins are not suspicious")
+
+	_, err = tokens.Generate(ctx, googleIdentity("test@example.com"), &types.Device{UserAgent: firefox, IP: "10.0.1.1"})
+	assert.Nil(t, err)
+	unverified := googleIdentity("test@example.com")
+	unverified.EmailVerified = false
+	_, err = tokens.Generate(ctx, unverified, &types.Device{UserAgent: chrome, IP: "10.0.0.1"})
+	assert.NotNil(t, err)
+	_, err = tokens.Generate(ctx, googleIdentity("nobody@example.com"), nil)
+	assert.NotNil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(events.PublishedLogins()) > 0
+	}, 5*time.Second, 10*time.Millisecond, "suspicious logins are published in the background")
+	published := events.PublishedLogins()
+	assert.Len(t, published, 1)
+	assert.Equal(t, types.EventSuspiciousLogin, published[0].Type)
+	assert.Equal(t, uint64(1), published[0].UserID)
+	assert.Equal(t, "Firefox on Linux", published[0].Device)
+	assert.Equal(t, "SG", published[0].Country)
+	assert.True(t, published[0].NewDevice)
+	assert.True(t, published[0].UnusualCountry)
+
+	history, err := logins.History(ctx, 1)
+	assert.Nil(t, err)
+	assert.Len(t, history, 4, "attempts of other emails and without any are not listed")
+	assert.False(t, history[0].Success)
+	assert.Equal(t, "emailUnverified", history[0].Reason)
+	assert.True(t, history[1].Success)
+	assert.True(t, history[1].NewDevice)
+	assert.Equal(t, "SG", history[1].Country)
+	assert.Equal(t, "Chrome on Windows", history[2].Device)
+	assert.False(t, history[2].NewDevice)
+	assert.Equal(t, "ID", history[3].Country)
+
+	// another machine describes the same, but is no device logged in from
+	other := strings.Replace(chrome, "Chrome/131", "Chrome/130", 1)
+	_, err = tokens.Generate(ctx, googleIdentity("test@example.com"), &types.Device{UserAgent: other, IP: "10.0.0.1"})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return len(events.PublishedLogins()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	published = events.PublishedLogins()
+	assert.Equal(t, "Chrome on Windows", published[1].Device)
+	assert.True(t, published[1].NewDevice)
+	assert.False(t, published[1].UnusualCountry)
+}
+
+// heldEvents publishes once released, reading the context only then.
+type heldEvents struct {
+	release chan struct{}
+	values  chan any
+}
+
+func (e *heldEvents) PublishLogin(ctx context.Context, event *types.LoginEvent) error {
+	<-e.release
+	e.values <- ctx.Value("tenant")
+	return nil
+}
+
+func TestRecordOutlivesRequest(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := newConfig()
+	events := &heldEvents{make(chan struct{}), make(chan any, 1)}
+	logins := usecase.NewLoginUsecase(repository.NewLoginFake(), newUsers(), events, nil, cfg, &log)
+	logins.Record(context.Background(), &types.LoginAttempt{
+		UserID:    1,
+		Email:     "test@example.com",
+		UserAgent: "Chrome",
+		Success:   true,
+	})
+
+	// fiber handlers get fasthttp's request context, reused once answered
+	request := new(fasthttp.RequestCtx)
+	logins.Record(request, &types.LoginAttempt{
+		UserID:    1,
+		Email:     "test@example.com",
+		UserAgent: "Firefox",
+		Success:   true,
+	})
+	request.SetUserValue("tenant", "the next request")
+	close(events.release)
+	select {
+	case value := <-events.values:
+		assert.Nil(t, value, "publishing keeps nothing of the answered request")
+	case <-time.After(5 * time.Second):
+		t.Fatal("suspicious login not published")
+	}
+}
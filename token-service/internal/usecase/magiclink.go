@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultMagicLinkTTL = 15 * time.Minute
+	// sendTimeout bounds mailing a link, which happens after the request
+	// was answered
+	sendTimeout = 30 * time.Second
+)
+
+type IMagicLinkUsecase interface {
+	// Send mails a login link to email when it is a registered user's, in
+	// the background so neither its timing nor its failures tell whether
+	// it is. Failures are only logged.
+	Send(ctx context.Context, email string)
+	// Verify logs in with the token of a link, once, the way Generate does.
+	Verify(ctx context.Context, token string, device *types.Device) (*types.TokenPair, error)
+}
+
+type magicLinkUsecase struct {
+	tokens ITokenUsecase
+	links  repository.IMagicLinkStorage
+	users  repository.IUserStorage
+	mailer repository.IMailer
+	cfg    *config.Config
+	log    *zerolog.Logger
+}
+
+func NewMagicLinkUsecase(
+	tokens ITokenUsecase,
+	links repository.IMagicLinkStorage,
+	users repository.IUserStorage,
+	mailer repository.IMailer,
+	cfg *config.Config,
+	log *zerolog.Logger,
+) IMagicLinkUsecase {
+	return &magicLinkUsecase{tokens, links, users, mailer, cfg, log}
+}
+
+func (u *magicLinkUsecase) Send(ctx context.Context, email string) {
+	// user-service matches emails as they were registered, case and all
+	email = strings.TrimSpace(email)
+	// The request is answered meanwhile, and fiber recycles its context for
+	// the next one once it is, so nothing of it may be kept.
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	go func() {
+		defer cancel()
+		if err := u.send(ctx, email); err != nil {
+			u.log.Error().Err(err).Str("email", email).Msg("sending magic link")
+		}
+	}()
+}
+
+func (u *magicLinkUsecase) send(ctx context.Context, email string) error {
+	if _, err := u.users.GetUserID(ctx, email); err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			u.log.Info().Str("email", email).Msg("magic link asked for unregistered email")
+			return nil
+		}
+		return fmt.Errorf("fetch user id by email of %s: %w", email, err)
+	}
+	ttl := defaultMagicLinkTTL
+	if u.cfg.MagicLink.TTL > 0 {
+		ttl = time.Duration(u.cfg.MagicLink.TTL) * time.Minute
+	}
+	token, err := randomString()
+	if err != nil {
+		return err
+	}
+	if err := u.links.CreateMagicLink(ctx, &types.MagicLink{
+		TokenHash: hashToken(token),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return err
+	}
+	page := u.cfg.MagicLink.URL
+	if page == "" {
+		page = strings.TrimSuffix(u.cfg.Auth.FrontendURL, "/") + "/login/magic"
+	}
+	// The token travels in the fragment so it stays out of server logs, and
+	// only the page posting it logs in, not mail scanners following links.
+	link := page + "#token=" + token
+	if err := u.mailer.Send(ctx, &types.Mail{
+		To:      email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Follow this link to log in, within %d minutes:\n\n%s\n\n"+
+				"It works once. If you did not ask to log in, ignore this email.\n",
+			int(ttl.Minutes()),
+			link,
+		),
+	}); err != nil {
+		return fmt.Errorf("mailing magic link: %w", err)
+	}
+	return nil
+}
+
+func (u *magicLinkUsecase) Verify(
+	ctx context.Context,
+	token string,
+	device *types.Device,
+) (*types.TokenPair, error) {
+	link, err := u.links.ConsumeMagicLink(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return nil, &Error{
+				Code:    http.StatusUnauthorized,
+				Message: msgInvalidMagicLink,
+			}
+		}
+		return nil, err
+	}
+	// Following the link proves the email is theirs.
+	return u.tokens.Generate(ctx, &types.Identity{
+		Provider:      types.ProviderEmail,
+		Email:         link.Email,
+		EmailVerified: true,
+	}, device)
+}
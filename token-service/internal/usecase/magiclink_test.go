@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubSMTP listens for SMTP like a local relay would, without STARTTLS
+// or auth, passing the data of every mail it accepts to the channel.
+func newStubSMTP(t *testing.T) (string, int, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { ln.Close() })
+	mails := make(chan string, 8)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, mails)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, mails
+}
+
+func serveSMTP(conn net.Conn, mails chan<- string) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 stub ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, _, _ := strings.Cut(strings.ToUpper(line), " ")
+		switch verb {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 stub")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 end with .")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mails <- string(data)
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 unknown")
+		}
+	}
+}
+
+func TestMagicLink(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Auth.FrontendURL = "https://icn.example.com"
+	host, port, mails := newStubSMTP(t)
+	links := repository.NewMagicLinkFake()
+	magic := usecase.NewMagicLinkUsecase(
+		newUsecaseWith(cfg),
+		links,
+		newUsers(),
+		repository.NewMailerSMTP(host, port, "", "", "noreply@icn.example.com"),
+		cfg,
+		&log,
+	)
+	code := func(err error) int {
+		uscErr := new(usecase.Error)
+		assert.True(t, errors.As(err, &uscErr))
+		return uscErr.Code
+	}
+
+	magic.Send(ctx, "stranger@example.com")
+	magic.Send(ctx, "Test@Example.com")
+	magic.Send(ctx, " test@example.com ")
+	var mail string
+	select {
+	case mail = <-mails:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail sent")
+	}
+	assert.Empty(t, mails, "only the registered email, as registered, gets mail")
+	assert.Contains(t, mail, "To: test@example.com")
+	match := regexp.MustCompile(`https://icn\.example\.com/login/magic#token=(\S+)`).FindStringSubmatch(mail)
+	assert.Len(t, match, 2)
+	token := match[1]
+
+	_, err := magic.Verify(ctx, "forged", nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err))
+	pair, err := magic.Verify(ctx, token, nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+	_, err = magic.Verify(ctx, token, nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err), "links work once")
+
+	sum := sha256.Sum256([]byte("expired"))
+	assert.Nil(t, links.CreateMagicLink(ctx, &types.MagicLink{
+		TokenHash: hex.EncodeToString(sum[:]),
+		Email:     "test@example.com",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	_, err = magic.Verify(ctx, "expired", nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err), "links expire")
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/totp"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+const (
+	defaultMFAIssuer       = "Lab ICN"
+	defaultMFAChallengeTTL = 5 * time.Minute
+	// maxMFAAttempts wrong codes end a challenge, the login starts over
+	maxMFAAttempts    = 5
+	recoveryCodeCount = 10
+)
+
+type IMFAUsecase interface {
+	// Enroll starts TOTP enrollment of admin id. Replacing a confirmed
+	// factor takes a login with it, whose methods amr holds.
+	Enroll(ctx context.Context, id uint64, amr []string) (*types.TOTPEnrollment, error)
+	// Confirm turns the enrollment of user id on with a code of it,
+	// returning recovery codes that replace any earlier ones.
+	Confirm(ctx context.Context, id uint64, code string) ([]string, error)
+}
+
+type mfaUsecase struct {
+	store repository.IMFAStorage
+	users repository.IUserStorage
+	cfg   *config.Config
+}
+
+func NewMFAUsecase(
+	store repository.IMFAStorage,
+	users repository.IUserStorage,
+	cfg *config.Config,
+) IMFAUsecase {
+	return &mfaUsecase{store, users, cfg}
+}
+
+func (u *mfaUsecase) Enroll(ctx context.Context, id uint64, amr []string) (*types.TOTPEnrollment, error) {
+	if err := authorizeAdmin(ctx, u.users, u.cfg, id); err != nil {
+		return nil, err
+	}
+	factor, err := u.store.GetMFAFactor(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNoRow) {
+		return nil, fmt.Errorf("fetch mfa factor of %d: %w", id, err)
+	}
+	// Anyone holding a stolen access token could swap the factor out
+	// otherwise.
+	if factor.Secret != "" && !slices.Contains(amr, types.AMRMFA) {
+		return nil, &Error{
+			Code:    http.StatusForbidden,
+			Message: msgMFARequired,
+		}
+	}
+	user, err := u.users.GetUser(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetch user %d: %w", id, err)
+	}
+	secret := totp.NewSecret()
+	if err := u.store.SetPendingMFASecret(ctx, id, secret); err != nil {
+		return nil, err
+	}
+	issuer := defaultMFAIssuer
+	if u.cfg.MFA.Issuer != "" {
+		issuer = u.cfg.MFA.Issuer
+	}
+	return &types.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(issuer, user.Email, secret),
+	}, nil
+}
+
+func (u *mfaUsecase) Confirm(ctx context.Context, id uint64, code string) ([]string, error) {
+	factor, err := u.store.GetMFAFactor(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNoRow) {
+		return nil, fmt.Errorf("fetch mfa factor of %d: %w", id, err)
+	}
+	if factor.PendingSecret == "" {
+		return nil, &Error{
+			Code:    http.StatusNotFound,
+			Message: msgNoPendingMFA,
+		}
+	}
+	step, ok := totp.Validate(factor.PendingSecret, code, time.Now())
+	if !ok {
+		return nil, &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidMFACode,
+		}
+	}
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := u.store.ConfirmMFAFactor(ctx, id, factor.PendingSecret, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return nil, &Error{
+				Code:    http.StatusConflict,
+				Message: msgMFAReenrolled,
+			}
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (u *usecase) Challenge(
+	ctx context.Context,
+	id uint64,
+	request *types.AuthorizationRequest,
+) (string, error) {
+	factor, err := u.mfa.GetMFAFactor(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNoRow) {
+		return "", fmt.Errorf("fetch mfa factor of %d: %w", id, err)
+	}
+	if factor.Secret == "" {
+		return "", nil
+	}
+	ttl := defaultMFAChallengeTTL
+	if u.cfg.MFA.ChallengeTTL > 0 {
+		ttl = time.Duration(u.cfg.MFA.ChallengeTTL) * time.Minute
+	}
+	token, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	if err := u.mfa.CreateMFAChallenge(ctx, &types.MFAChallenge{
+		TokenHash:     hashToken(token),
+		UserID:        id,
+		Authorization: request,
+		ExpiresAt:     time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *usecase) VerifyMFA(
+	ctx context.Context,
+	params *types.MFAParams,
+	device *types.Device,
+) (*types.TokenPair, error) {
+	if device == nil {
+		device = new(types.Device)
+	}
+	challenge, amr, err := u.answerChallenge(ctx, params, false)
+	if err != nil {
+		return nil, err
+	}
+	return u.startSession(ctx, challenge.UserID, device, amr)
+}
+
+func (u *usecase) AnswerMFA(
+	ctx context.Context,
+	params *types.MFAParams,
+) (*types.MFAChallenge, []string, error) {
+	return u.answerChallenge(ctx, params, true)
+}
+
+// answerChallenge takes the challenge of params off once its code proves the second
+// factor, returning the methods it proves. authorization tells whether the
+// challenge is expected to hold back a relying party login, one is only
+// ever answered where its login started.
+func (u *usecase) answerChallenge(
+	ctx context.Context,
+	params *types.MFAParams,
+	authorization bool,
+) (*types.MFAChallenge, []string, error) {
+	invalid := &Error{
+		Code:    http.StatusUnauthorized,
+		Message: msgInvalidMFAToken,
+	}
+	hash := hashToken(params.Token)
+	challenge, err := u.mfa.GetMFAChallenge(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return nil, nil, invalid
+		}
+		return nil, nil, err
+	}
+	if (challenge.Authorization != nil) != authorization {
+		return nil, nil, invalid
+	}
+	amr, err := u.verifyFactor(ctx, challenge.UserID, params.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amr == nil {
+		attempts, err := u.mfa.FailMFAChallenge(ctx, hash)
+		if err != nil && !errors.Is(err, repository.ErrNoRow) {
+			return nil, nil, err
+		}
+		if attempts >= maxMFAAttempts {
+			if err := u.mfa.DeleteMFAChallenge(ctx, hash); err != nil &&
+				!errors.Is(err, repository.ErrNoRowAffected) {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, &Error{
+			Code:    http.StatusUnauthorized,
+			Message: msgInvalidMFACode,
+		}
+	}
+	// Only one of two requests answering the same challenge gets through.
+	if err := u.mfa.DeleteMFAChallenge(ctx, hash); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return nil, nil, invalid
+		}
+		return nil, nil, err
+	}
+	return &challenge, amr, nil
+}
+
+// verifyFactor is the methods code proves user id authenticated with, nil
+// when it proves nothing. Every code is only taken once.
+func (u *usecase) verifyFactor(ctx context.Context, id uint64, code string) ([]string, error) {
+	factor, err := u.mfa.GetMFAFactor(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetch mfa factor of %d: %w", id, err)
+	}
+	if step, ok := totp.Validate(factor.Secret, code, time.Now()); ok {
+		if err := u.mfa.UseMFAStep(ctx, id, step); err != nil {
+			if errors.Is(err, repository.ErrNoRowAffected) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []string{types.AMROTP, types.AMRMFA}, nil
+	}
+	if err := u.mfa.UseRecoveryCode(ctx, id, hashRecoveryCode(code)); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []string{types.AMRRecovery, types.AMRMFA}, nil
+}
+
+// newRecoveryCode is 50 random bits as two groups of five characters, easy
+// enough to type off paper.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes of code as typed.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashToken(code)
+}
+
+// hashToken is what is stored of random tokens, which are long enough that
+// a plain hash keeps them safe.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/totp"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFA(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	store := repository.NewMFAFake()
+	tokens := usecase.NewTokenUsecase(repository.NewTokenFake(), newUsers(), newLogins(cfg), store, cfg, &log)
+	mfa := usecase.NewMFAUsecase(store, newUsers(), cfg)
+	code := func(err error) int {
+		uscErr := new(usecase.Error)
+		assert.True(t, errors.As(err, &uscErr))
+		return uscErr.Code
+	}
+
+	_, err := mfa.Enroll(ctx, 1, nil)
+	assert.Equal(t, http.StatusForbidden, code(err), "only admins enroll")
+	enrollment, err := mfa.Enroll(ctx, 2, nil)
+	assert.Nil(t, err)
+	assert.Contains(t, enrollment.URI, "admin@example.com")
+	_, err = mfa.Confirm(ctx, 2, "000000")
+	assert.Equal(t, http.StatusUnprocessableEntity, code(err))
+	now, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	assert.Nil(t, err)
+	recoveryCodes, err := mfa.Confirm(ctx, 2, now)
+	assert.Nil(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	pair, err := tokens.Generate(ctx, googleIdentity("admin@example.com"), nil)
+	assert.Nil(t, err)
+	assert.Empty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.MFAToken)
+	_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: now}, nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err), "the code confirming enrollment is used up")
+	next, err := totp.Code(enrollment.Secret, totp.Step(time.Now())+1)
+	assert.Nil(t, err)
+	verified, err := tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: next}, nil)
+	assert.Nil(t, err)
+	_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: next}, nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err), "challenges are answered once")
+
+	access, err := tokens.Refresh(ctx, 2, verified.RefreshToken, "127.0.0.1")
+	assert.Nil(t, err)
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(access, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{types.AMROTP, types.AMRMFA}, claims.AMR, "refreshed tokens keep the methods")
+
+	pair, err = tokens.Generate(ctx, googleIdentity("admin@example.com"), nil)
+	assert.Nil(t, err)
+	verified, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: " " + recoveryCodes[0]}, nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, verified.AccessToken)
+	pair, err = tokens.Generate(ctx, googleIdentity("admin@example.com"), nil)
+	assert.Nil(t, err)
+	_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: recoveryCodes[0]}, nil)
+	assert.Equal(t, http.StatusUnauthorized, code(err), "recovery codes are used once")
+
+	_, err = mfa.Enroll(ctx, 2, nil)
+	assert.Equal(t, http.StatusForbidden, code(err), "replacing the factor takes a login with it")
+	_, err = mfa.Enroll(ctx, 2, []string{types.AMROTP, types.AMRMFA})
+	assert.Nil(t, err)
+
+	pair, err = tokens.Generate(ctx, googleIdentity("admin2@example.com"), nil)
+	assert.Nil(t, err)
+	assert.Empty(t, pair.MFAToken, "admins without a factor log in to enroll one")
+}
+
+func TestMFAAttempts(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	store := repository.NewMFAFake()
+	tokens := usecase.NewTokenUsecase(repository.NewTokenFake(), newUsers(), newLogins(cfg), store, cfg, &log)
+	mfa := usecase.NewMFAUsecase(store, newUsers(), cfg)
+	enrollment, err := mfa.Enroll(ctx, 2, nil)
+	assert.Nil(t, err)
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	assert.Nil(t, err)
+	_, err = mfa.Confirm(ctx, 2, code)
+	assert.Nil(t, err)
+
+	pair, err := tokens.Generate(ctx, googleIdentity("admin@example.com"), nil)
+	assert.Nil(t, err)
+	for range 5 {
+		_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: "wrong"}, nil)
+		assert.NotNil(t, err)
+	}
+	code, err = totp.Code(enrollment.Secret, totp.Step(time.Now())+1)
+	assert.Nil(t, err)
+	_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: code}, nil)
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusUnauthorized, uscErr.Code, "too many wrong codes end the challenge")
+}
+
+func TestMFAChallengeAnsweredWhereStarted(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	store := repository.NewMFAFake()
+	tokens := usecase.NewTokenUsecase(repository.NewTokenFake(), newUsers(), newLogins(cfg), store, cfg, &log)
+	mfa := usecase.NewMFAUsecase(store, newUsers(), cfg)
+	enrollment, err := mfa.Enroll(ctx, 2, nil)
+	assert.Nil(t, err)
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	assert.Nil(t, err)
+	_, err = mfa.Confirm(ctx, 2, code)
+	assert.Nil(t, err)
+	code, err = totp.Code(enrollment.Secret, totp.Step(time.Now())+1)
+	assert.Nil(t, err)
+	uscErr := new(usecase.Error)
+
+	token, err := tokens.Challenge(ctx, 1, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, token, "users without a factor are not challenged")
+
+	request := &types.AuthorizationRequest{ClientID: "grafana", RedirectURI: relyingPartyURI}
+	token, err = tokens.Challenge(ctx, 2, request)
+	assert.Nil(t, err)
+	_, err = tokens.VerifyMFA(ctx, &types.MFAParams{Token: token, Code: code}, nil)
+	assert.True(t, errors.As(err, &uscErr), "relying party logins start no session")
+	assert.Equal(t, http.StatusUnauthorized, uscErr.Code)
+
+	pair, err := tokens.Generate(ctx, googleIdentity("admin@example.com"), nil)
+	assert.Nil(t, err)
+	_, _, err = tokens.AnswerMFA(ctx, &types.MFAParams{Token: pair.MFAToken, Code: code})
+	assert.True(t, errors.As(err, &uscErr), "nor do frontend logins get codes")
+	assert.Equal(t, http.StatusUnauthorized, uscErr.Code)
+
+	challenge, amr, err := tokens.AnswerMFA(ctx, &types.MFAParams{Token: token, Code: code})
+	assert.Nil(t, err, "refused answers leave the challenge be")
+	assert.Equal(t, uint64(2), challenge.UserID)
+	assert.Equal(t, request, challenge.Authorization)
+	assert.Equal(t, []string{types.AMROTP, types.AMRMFA}, amr)
+}
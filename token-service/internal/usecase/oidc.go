@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	scopeOpenID            = "openid"
+	scopeProfile           = "profile"
+	scopeEmail             = "email"
+	scopeGroups            = "groups"
+)
+
+// oidcScopes are all relying parties may ask for, others are dropped.
+var oidcScopes = []string{scopeOpenID, scopeProfile, scopeEmail, scopeGroups}
+
+// IOIDCUsecase lets token-service act as the OpenID Connect provider of
+// the lab's own tools, logging users in through an upstream provider.
+type IOIDCUsecase interface {
+	Configuration() *types.OpenIDConfiguration
+	// Keys is the JWK set ID tokens are verified with.
+	Keys() ([]byte, error)
+	// Authorize checks an authentication request and starts the login,
+	// returning where to send the user. Requests that cannot be answered
+	// at the redirect URI fail instead.
+	Authorize(ctx context.Context, request *types.AuthorizationRequest) (*types.AuthRedirect, error)
+	// Exchange serves the authorization code grant of the token endpoint.
+	Exchange(ctx context.Context, params *types.TokenParams) (*types.OAuthToken, error)
+	// ExchangeToken trades an access token for one limited to an audience
+	// and scopes, living shorter, as RFC 8693 token exchange. It is signed
+	// with the key of Keys.
+	ExchangeToken(ctx context.Context, params *types.TokenParams) (*types.OAuthToken, error)
+	UserInfo(ctx context.Context, accessToken string) (*types.UserInfo, error)
+}
+
+type oidcUsecase struct {
+	auth    IAuthUsecase
+	clients repository.IClientStorage
+	codes   repository.IAuthorizationCodeStorage
+	users   repository.IUserStorage
+	tokens  repository.ITokenStorage
+	key     *rsa.PrivateKey
+	kid     string
+	cfg     *config.Config
+}
+
+func NewOIDCUsecase(
+	auth IAuthUsecase,
+	clients repository.IClientStorage,
+	codes repository.IAuthorizationCodeStorage,
+	users repository.IUserStorage,
+	tokens repository.ITokenStorage,
+	key *rsa.PrivateKey,
+	cfg *config.Config,
+) IOIDCUsecase {
+	return &oidcUsecase{auth, clients, codes, users, tokens, key, jwks.Thumbprint(&key.PublicKey), cfg}
+}
+
+func (u *oidcUsecase) Configuration() *types.OpenIDConfiguration {
+	issuer := u.issuer()
+	return &types.OpenIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/v1/oauth/authorize",
+		TokenEndpoint:                     issuer + "/v1/oauth/token",
+		UserinfoEndpoint:                  issuer + "/v1/oauth/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   oidcScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{GrantAuthorizationCode, GrantClientCredentials, GrantTokenExchange},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "amr",
+			"email", "email_verified", "name", "preferred_username", "picture", "groups",
+		},
+	}
+}
+
+func (u *oidcUsecase) Keys() ([]byte, error) {
+	return jwks.Encode(map[string]*rsa.PublicKey{u.kid: &u.key.PublicKey})
+}
+
+func (u *oidcUsecase) Authorize(ctx context.Context, request *types.AuthorizationRequest) (*types.AuthRedirect, error) {
+	client, err := u.clients.GetClient(ctx, request.ClientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return nil, &Error{
+				Code:    http.StatusBadRequest,
+				Message: msgUnknownClient,
+			}
+		}
+		return nil, fmt.Errorf("fetch client %s: %w", request.ClientID, err)
+	}
+	// Until the redirect URI is known to be the client's, errors must not
+	// be sent there.
+	if !slices.Contains(client.RedirectURIs, request.RedirectURI) {
+		return nil, &Error{
+			Code:    http.StatusBadRequest,
+			Message: msgInvalidRedirectURI,
+		}
+	}
+	if request.ResponseType != "code" {
+		return authorizationError(request, "unsupported_response_type", "only the code flow is supported")
+	}
+	scopes := []string{}
+	for _, scope := range strings.Fields(request.Scope) {
+		if slices.Contains(oidcScopes, scope) && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if !slices.Contains(scopes, scopeOpenID) {
+		return authorizationError(request, oauthInvalidScope, "scope must include openid")
+	}
+	request.Scope = strings.Join(scopes, " ")
+	if request.CodeChallenge != "" && request.CodeChallengeMethod != "S256" {
+		return authorizationError(request, oauthInvalidRequest, "code_challenge_method must be S256")
+	}
+	providerName := u.cfg.OIDC.Provider
+	if providerName == "" {
+		providerName = types.ProviderGoogle
+	}
+	return u.auth.StartAuthorization(ctx, providerName, request)
+}
+
+// authorizationError answers a request at its redirect URI, as RFC 6749
+// section 4.1.2.1 has it.
+func authorizationError(request *types.AuthorizationRequest, reason, description string) (*types.AuthRedirect, error) {
+	location, err := url.Parse(request.RedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("parse redirect uri of client %s: %w", request.ClientID, err)
+	}
+	query := location.Query()
+	query.Set("error", reason)
+	query.Set("error_description", description)
+	if request.State != "" {
+		query.Set("state", request.State)
+	}
+	location.RawQuery = query.Encode()
+	return &types.AuthRedirect{Location: location.String()}, nil
+}
+
+func (u *oidcUsecase) Exchange(ctx context.Context, params *types.TokenParams) (*types.OAuthToken, error) {
+	client, err := authenticateClient(ctx, u.clients, params.ClientID, params.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	invalid := &OAuthError{
+		Code:   http.StatusBadRequest,
+		Reason: oauthInvalidGrant,
+	}
+	code, err := u.codes.ConsumeAuthorizationCode(ctx, params.Code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("consume authorization code: %w", err)
+	}
+	if code.ClientID != client.ID || code.RedirectURI != params.RedirectURI {
+		return nil, invalid
+	}
+	if code.Challenge != "" {
+		sum := sha256.Sum256([]byte(params.CodeVerifier))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != code.Challenge {
+			return nil, invalid
+		}
+	}
+	claims, err := u.userClaims(ctx, code.UserID, strings.Fields(code.Scope))
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Duration(u.cfg.JWT.AccessTTL) * time.Minute
+	now := time.Now().UTC()
+	subject := fmt.Sprint(code.UserID)
+	access := jwt.NewWithClaims(
+		jwt.SigningMethodHS512,
+		types.ClientClaims{
+			ClientID: client.ID,
+			Scope:    code.Scope,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    u.issuer(),
+				Subject:   subject,
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+		},
+	)
+	accessToken, err := access.SignedString([]byte(u.cfg.JWT.Key))
+	if err != nil {
+		return nil, fmt.Errorf("signing relying party access token: %w", err)
+	}
+	id := jwt.NewWithClaims(
+		jwt.SigningMethodRS256,
+		types.IDClaims{
+			Nonce:      code.Nonce,
+			AuthTime:   jwt.NewNumericDate(code.AuthTime),
+			AMR:        code.AMR,
+			UserClaims: claims,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    u.issuer(),
+				Subject:   subject,
+				Audience:  jwt.ClaimStrings{client.ID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+		},
+	)
+	id.Header["kid"] = u.kid
+	idToken, err := id.SignedString(u.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing id token: %w", err)
+	}
+	return &types.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		Scope:       code.Scope,
+		IDToken:     idToken,
+	}, nil
+}
+
+func (u *oidcUsecase) UserInfo(ctx context.Context, accessToken string) (*types.UserInfo, error) {
+	invalid := &Error{
+		Code:    http.StatusUnauthorized,
+		Message: msgInvalidAccessToken,
+	}
+	claims := new(types.ClientClaims)
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(u.cfg.JWT.Key), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Alg()}))
+	if err != nil {
+		invalid.Err = err
+		return nil, invalid
+	}
+	scopes := strings.Fields(claims.Scope)
+	if claims.ClientID == "" || !slices.Contains(scopes, scopeOpenID) {
+		return nil, invalid
+	}
+	// client credentials tokens have the client as subject
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	userClaims, err := u.userClaims(ctx, id, scopes)
+	if err != nil {
+		return nil, err
+	}
+	return &types.UserInfo{Subject: claims.Subject, UserClaims: userClaims}, nil
+}
+
+// userClaims are the claims about user id that scopes release.
+func (u *oidcUsecase) userClaims(ctx context.Context, id uint64, scopes []string) (types.UserClaims, error) {
+	user, err := u.users.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return types.UserClaims{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotRegistered,
+			}
+		}
+		return types.UserClaims{}, fmt.Errorf("fetch user %d: %w", id, err)
+	}
+	claims := types.UserClaims{}
+	if slices.Contains(scopes, scopeProfile) {
+		claims.Name = user.Fullname
+		claims.PreferredUsername = user.Username
+		claims.Picture = user.AvatarURL
+	}
+	// users only ever log in with an email the login policy saw verified
+	if slices.Contains(scopes, scopeEmail) {
+		claims.Email = user.Email
+		claims.EmailVerified = true
+	}
+	if slices.Contains(scopes, scopeGroups) {
+		groups, err := u.users.GetGroupNames(ctx, id)
+		if err != nil {
+			return types.UserClaims{}, fmt.Errorf("fetch group names of user %d: %w", id, err)
+		}
+		claims.Groups = groups
+	}
+	return claims, nil
+}
+
+func (u *oidcUsecase) issuer() string {
+	return strings.TrimSuffix(u.cfg.Auth.BaseURL, "/")
+}
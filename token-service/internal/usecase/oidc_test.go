@@ -0,0 +1,246 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/token-service/internal/jwks"
+	"github.com/Lab-ICN/backend/token-service/internal/provider"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/totp"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const relyingPartyURI = "https://grafana.example.com/login/generic_oauth"
+
+// newOIDC wires an identity provider logging users in through a stub
+// issuer, with one relying party registered.
+func newOIDC(t *testing.T) (usecase.IAuthUsecase, usecase.IOIDCUsecase, string, string) {
+	return newOIDCWith(t, repository.NewMFAFake())
+}
+
+// newOIDCWith is newOIDC with the second factors of mfa.
+func newOIDCWith(
+	t *testing.T,
+	mfa repository.IMFAStorage,
+) (usecase.IAuthUsecase, usecase.IOIDCUsecase, string, string) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Auth.BaseURL = "https://example.com/backend"
+	cfg.OIDC.Provider = "sso"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	clients := repository.NewClientFake()
+	codes := repository.NewAuthorizationCodeFake()
+	auth := usecase.NewAuthUsecase(
+		usecase.NewTokenUsecase(repository.NewTokenFake(), newUsers(), newLogins(cfg), mfa, cfg, &log),
+		newLogins(cfg),
+		repository.NewAuthStateFake(),
+		codes,
+		map[string]provider.IProvider{
+			"sso": provider.NewOIDC(issuer.Client(), issuer.URL, "client", "secret", &log),
+		},
+		cfg,
+	)
+	users := repository.NewUserFake(
+		map[string]uint64{"test@example.com": 1},
+		map[uint64][]string{1: {"networking"}},
+	)
+	oidc := usecase.NewOIDCUsecase(auth, clients, codes, users, repository.NewTokenFake(), key, cfg)
+	id, secret, err := usecase.NewClientUsecase(clients, cfg).Register(ctx, "grafana", nil, []string{relyingPartyURI})
+	assert.Nil(t, err)
+	return auth, oidc, id, secret
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	auth, oidc, id, secret := newOIDC(t)
+	verifier := "verifier-of-the-relying-party"
+	challenge := sha256.Sum256([]byte(verifier))
+
+	upstreamRedirect, err := oidc.Authorize(ctx, &types.AuthorizationRequest{
+		ClientID:            id,
+		RedirectURI:         relyingPartyURI,
+		ResponseType:        "code",
+		Scope:               "openid email groups unknown",
+		State:               "rp-state",
+		Nonce:               "rp-nonce",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(challenge[:]),
+		CodeChallengeMethod: "S256",
+	})
+	assert.Nil(t, err)
+	upstream, err := url.Parse(upstreamRedirect.Location)
+	assert.Nil(t, err)
+	assert.Equal(t, upstream.Query().Get("state"), upstreamRedirect.State)
+	resp, err := http.Get(upstreamRedirect.Location)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	result, err := auth.Callback(ctx, "sso", &types.CallbackParams{
+		State:      upstreamRedirect.State,
+		Code:       "code",
+		BoundState: upstreamRedirect.State,
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, result.AccessToken)
+	location, err := url.Parse(result.Location)
+	assert.Nil(t, err)
+	assert.Equal(t, relyingPartyURI, location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "rp-state", location.Query().Get("state"))
+
+	params := &types.TokenParams{
+		ClientID:     id,
+		ClientSecret: secret,
+		Code:         location.Query().Get("code"),
+		RedirectURI:  relyingPartyURI,
+		CodeVerifier: verifier,
+	}
+	token, err := oidc.Exchange(ctx, params)
+	assert.Nil(t, err)
+	assert.Equal(t, "openid email groups", token.Scope)
+
+	content, err := oidc.Keys()
+	assert.Nil(t, err)
+	keys, err := jwks.Parse(content)
+	assert.Nil(t, err)
+	claims := new(types.IDClaims)
+	_, err = jwt.ParseWithClaims(token.IDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return keys[t.Header["kid"].(string)], nil
+	}, jwt.WithAudience(id), jwt.WithIssuer("https://example.com/backend"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "rp-nonce", claims.Nonce)
+	assert.Equal(t, "test@example.com", claims.Email)
+	assert.Empty(t, claims.AMR)
+
+	info, err := oidc.UserInfo(ctx, token.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", info.Subject)
+	assert.Equal(t, "test@example.com", info.Email)
+	assert.Equal(t, []string{"networking"}, info.Groups)
+	assert.Empty(t, info.Name)
+
+	// codes are single use
+	_, err = oidc.Exchange(ctx, params)
+	oauthErr := new(usecase.OAuthError)
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_grant", oauthErr.Reason)
+}
+
+func TestOIDCAuthorizationMFA(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMFAFake()
+	secret := totp.NewSecret()
+	assert.Nil(t, store.SetPendingMFASecret(ctx, 1, secret))
+	assert.Nil(t, store.ConfirmMFAFactor(ctx, 1, secret, 0, nil))
+	auth, oidc, id, clientSecret := newOIDCWith(t, store)
+
+	upstreamRedirect, err := oidc.Authorize(ctx, &types.AuthorizationRequest{
+		ClientID:     id,
+		RedirectURI:  relyingPartyURI,
+		ResponseType: "code",
+		Scope:        "openid",
+		State:        "rp-state",
+	})
+	assert.Nil(t, err)
+	resp, err := http.Get(upstreamRedirect.Location)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	result, err := auth.Callback(ctx, "sso", &types.CallbackParams{
+		State:      upstreamRedirect.State,
+		Code:       "code",
+		BoundState: upstreamRedirect.State,
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, result.Location, "no code before the second factor")
+	assert.True(t, result.Authorize)
+	assert.NotEmpty(t, result.MFAToken)
+
+	_, err = auth.VerifyMFA(ctx, &types.MFAParams{Token: result.MFAToken, Code: "wrong"})
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusUnauthorized, uscErr.Code)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.Nil(t, err)
+	answered, err := auth.VerifyMFA(ctx, &types.MFAParams{Token: result.MFAToken, Code: code})
+	assert.Nil(t, err)
+	location, err := url.Parse(answered.Location)
+	assert.Nil(t, err)
+	assert.Equal(t, relyingPartyURI, location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "rp-state", location.Query().Get("state"))
+
+	token, err := oidc.Exchange(ctx, &types.TokenParams{
+		ClientID:     id,
+		ClientSecret: clientSecret,
+		Code:         location.Query().Get("code"),
+		RedirectURI:  relyingPartyURI,
+	})
+	assert.Nil(t, err)
+	claims := new(types.IDClaims)
+	_, _, err = jwt.NewParser().ParseUnverified(token.IDToken, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{types.AMROTP, types.AMRMFA}, claims.AMR)
+}
+
+func TestOIDCAuthorizeRefused(t *testing.T) {
+	ctx := context.Background()
+	_, oidc, id, _ := newOIDC(t)
+
+	_, err := oidc.Authorize(ctx, &types.AuthorizationRequest{
+		ClientID:     id,
+		RedirectURI:  "https://evil.example.com/callback",
+		ResponseType: "code",
+		Scope:        "openid",
+	})
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusBadRequest, uscErr.Code)
+
+	refusal, err := oidc.Authorize(ctx, &types.AuthorizationRequest{
+		ClientID:     id,
+		RedirectURI:  relyingPartyURI,
+		ResponseType: "code",
+		Scope:        "email",
+		State:        "rp-state",
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, refusal.State, "refusals start no login to bind")
+	redirect, err := url.Parse(refusal.Location)
+	assert.Nil(t, err)
+	assert.Equal(t, "invalid_scope", redirect.Query().Get("error"))
+	assert.Equal(t, "rp-state", redirect.Query().Get("state"))
+}
+
+func TestOIDCUserInfoRefusesClientTokens(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	clients := repository.NewClientFake()
+	u := usecase.NewClientUsecase(clients, cfg)
+	id, secret, err := u.Register(ctx, "cron", []string{"users:read"}, nil)
+	assert.Nil(t, err)
+	token, err := u.Grant(ctx, usecase.GrantClientCredentials, &types.TokenParams{
+		ClientID:     id,
+		ClientSecret: secret,
+	})
+	assert.Nil(t, err)
+
+	_, oidc, _, _ := newOIDC(t)
+	_, err = oidc.UserInfo(ctx, token.AccessToken)
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusUnauthorized, uscErr.Code)
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+// authorize applies the login restrictions of the config to an identity the
+// provider already vouched for. Every refusal carries its own reason so the
+// frontend can tell the user what to do about it.
+func (u *usecase) authorize(identity *types.Identity) error {
+	if !identity.EmailVerified {
+		return refuse(reasonEmailUnverified, msgEmailUnverified)
+	}
+	_, domain, _ := strings.Cut(strings.ToLower(identity.Email), "@")
+	hostedDomain := strings.ToLower(identity.HostedDomain)
+	if identity.Provider == types.ProviderGoogle &&
+		len(u.cfg.Login.HostedDomains) > 0 &&
+		!slices.ContainsFunc(u.cfg.Login.HostedDomains, func(allowed string) bool {
+			return strings.EqualFold(allowed, hostedDomain)
+		}) {
+		return refuse(reasonHostedDomainNotAllowed, msgHostedDomainNotAllowed)
+	}
+	for _, rule := range u.cfg.Login.Domains {
+		if !strings.EqualFold(rule.Domain, domain) {
+			continue
+		}
+		if rule.Deny {
+			return refuse(reasonDomainDenied, msgDomainDenied)
+		}
+		if len(rule.Providers) > 0 && !slices.Contains(rule.Providers, identity.Provider) {
+			return refuse(reasonProviderNotAllowed, msgProviderNotAllowed)
+		}
+		if rule.RequireHostedDomain &&
+			(identity.Provider != types.ProviderGoogle || hostedDomain != domain) {
+			return refuse(reasonHostedDomainRequired, msgHostedDomainRequired)
+		}
+	}
+	return nil
+}
+
+func refuse(reason, message string) error {
+	return &Error{
+		Code:    http.StatusForbidden,
+		Message: message,
+		Errors: []DomainError{{
+			Reason:   reason,
+			Message:  message,
+			Location: "token",
+		}},
+	}
+}
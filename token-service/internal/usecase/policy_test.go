@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertRefused(t *testing.T, err error, reason string) {
+	t.Helper()
+	uscErr := new(usecase.Error)
+	if assert.True(t, errors.As(err, &uscErr)) {
+		assert.Equal(t, http.StatusForbidden, uscErr.Code)
+		if assert.Len(t, uscErr.Errors, 1) {
+			assert.Equal(t, reason, uscErr.Errors[0].Reason)
+		}
+	}
+}
+
+func TestGenerateEmailUnverified(t *testing.T) {
+	ctx := context.Background()
+	identity := googleIdentity("test@example.com")
+	identity.EmailVerified = false
+	_, err := newUsecase().Generate(ctx, identity, nil)
+	assertRefused(t, err, "emailUnverified")
+}
+
+func TestGenerateHostedDomain(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	cfg.Login.HostedDomains = []string{"example.com"}
+	u := newUsecaseWith(cfg)
+	_, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assertRefused(t, err, "hostedDomainNotAllowed")
+	identity := googleIdentity("test@example.com")
+	identity.HostedDomain = "example.com"
+	_, err = u.Generate(ctx, identity, nil)
+	assert.Nil(t, err)
+}
+
+func TestGenerateDomainRules(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	err := json.Unmarshal([]byte(`{"login": {"domains": [
+		{"domain": "blocked.com", "deny": true},
+		{"domain": "example.com", "providers": ["google"], "requireHostedDomain": true}
+	]}}`), cfg)
+	assert.Nil(t, err)
+	u := newUsecaseWith(cfg)
+
+	_, err = u.Generate(ctx, googleIdentity("test@blocked.com"), nil)
+	assertRefused(t, err, "domainDenied")
+
+	github := googleIdentity("test@example.com")
+	github.Provider = types.ProviderGitHub
+	_, err = u.Generate(ctx, github, nil)
+	assertRefused(t, err, "providerNotAllowed")
+
+	_, err = u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assertRefused(t, err, "hostedDomainRequired")
+
+	managed := googleIdentity("test@example.com")
+	managed.HostedDomain = "example.com"
+	_, err = u.Generate(ctx, managed, nil)
+	assert.Nil(t, err)
+}
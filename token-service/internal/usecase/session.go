@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+)
+
+type ISessionUsecase interface {
+	// List is the active sessions of user id, marking current.
+	List(ctx context.Context, id, current uint64) ([]types.Session, error)
+	Revoke(ctx context.Context, id, session uint64) error
+	RevokeAll(ctx context.Context, id uint64) error
+	// AuthorizeAdmin refuses user id unless they are an admin, who may
+	// manage the sessions of anyone.
+	AuthorizeAdmin(ctx context.Context, id uint64) error
+}
+
+type sessionUsecase struct {
+	store repository.ITokenStorage
+	users repository.IUserStorage
+	cfg   *config.Config
+}
+
+func NewSessionUsecase(
+	store repository.ITokenStorage,
+	users repository.IUserStorage,
+	cfg *config.Config,
+) ISessionUsecase {
+	return &sessionUsecase{store, users, cfg}
+}
+
+func (u *sessionUsecase) List(ctx context.Context, id, current uint64) ([]types.Session, error) {
+	sessions, err := u.store.ListSessions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Device = describeDevice(sessions[i].UserAgent)
+		sessions[i].Current = current != 0 && sessions[i].ID == current
+	}
+	return sessions, nil
+}
+
+func (u *sessionUsecase) Revoke(ctx context.Context, id, session uint64) error {
+	if err := u.store.DeleteSession(ctx, id, session); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return &Error{
+				Code:    http.StatusNotFound,
+				Message: msgSessionNotFound,
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *sessionUsecase) RevokeAll(ctx context.Context, id uint64) error {
+	return u.store.DeleteSessions(ctx, id)
+}
+
+func (u *sessionUsecase) AuthorizeAdmin(ctx context.Context, id uint64) error {
+	return authorizeAdmin(ctx, u.users, u.cfg, id)
+}
+
+// browsers and systems are matched in order, the first hit wins, since user
+// agents name several for compatibility.
+var (
+	browsers = [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	systems = [][2]string{
+		{"Windows", "Windows"},
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// describeDevice is a readable guess of the browser and operating system
+// behind a user agent.
+func describeDevice(userAgent string) string {
+	match := func(table [][2]string) string {
+		for _, entry := range table {
+			if strings.Contains(userAgent, entry[0]) {
+				return entry[1]
+			}
+		}
+		return ""
+	}
+	browser, system := match(browsers), match(systems)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
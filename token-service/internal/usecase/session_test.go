@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	store := repository.NewTokenFake()
+	users := repository.NewUserFake(
+		map[string]uint64{"test@example.com": 1, "admin@example.com": 2},
+		map[uint64][]string{1: {"networking"}, 2: {"admin"}},
+	)
+	tokens := usecase.NewTokenUsecase(store, users, newLogins(cfg), repository.NewMFAFake(), cfg, &log)
+	sessions := usecase.NewSessionUsecase(store, users, cfg)
+
+	laptop := &types.Device{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		IP:        "10.0.0.1",
+	}
+	phone := &types.Device{
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 18_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Mobile/15E148 Safari/604.1",
+		IP:        "10.0.0.2",
+	}
+	_, err := tokens.Generate(ctx, googleIdentity("test@example.com"), laptop)
+	assert.Nil(t, err)
+	phoneTokens, err := tokens.Generate(ctx, googleIdentity("test@example.com"), phone)
+	assert.Nil(t, err)
+
+	list, err := sessions.List(ctx, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	devices := map[string]types.Session{}
+	for _, session := range list {
+		devices[session.Device] = session
+	}
+	assert.True(t, devices["Chrome on Windows"].Current)
+	assert.Equal(t, "10.0.0.1", devices["Chrome on Windows"].IP)
+	assert.False(t, devices["Safari on iOS"].Current)
+
+	assert.Nil(t, sessions.Revoke(ctx, 1, devices["Safari on iOS"].ID))
+	_, err = tokens.Refresh(ctx, 1, phoneTokens.RefreshToken, "10.0.0.2")
+	assert.NotNil(t, err)
+	err = sessions.Revoke(ctx, 2, devices["Chrome on Windows"].ID)
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusNotFound, uscErr.Code)
+
+	assert.Nil(t, sessions.RevokeAll(ctx, 1))
+	list, err = sessions.List(ctx, 1, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+}
+
+func TestSessionsAuthorizeAdmin(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	sessions := usecase.NewSessionUsecase(
+		repository.NewTokenFake(),
+		repository.NewUserFake(nil, map[uint64][]string{1: {"networking"}, 2: {"admin"}}),
+		cfg,
+	)
+	assert.Nil(t, sessions.AuthorizeAdmin(ctx, 2))
+	err := sessions.AuthorizeAdmin(ctx, 1)
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusForbidden, uscErr.Code)
+}
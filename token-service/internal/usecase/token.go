@@ -5,126 +5,367 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"slices"
 	"time"
 
 	"github.com/Lab-ICN/backend/token-service/internal/config"
 	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
 )
 
 type ITokenUsecase interface {
-	Generate(ctx context.Context, email string) (string, string, error)
-	Refresh(ctx context.Context, id uint64) (string, error)
-	Invalidate(ctx context.Context, id uint64) error
+	// Authenticate resolves an identity logging in from device to the user
+	// it may log in as, recording the attempt either way.
+	Authenticate(ctx context.Context, identity *types.Identity, device *types.Device) (uint64, error)
+	// Generate logs the identity in from device, starting a session. Users
+	// with a second factor get an MFA challenge instead.
+	Generate(ctx context.Context, identity *types.Identity, device *types.Device) (*types.TokenPair, error)
+	// Challenge holds the login of user id back behind an MFA challenge,
+	// returning its token, or nothing for users without a second factor.
+	// request is the relying party login it stands for, if any.
+	Challenge(ctx context.Context, id uint64, request *types.AuthorizationRequest) (string, error)
+	// VerifyMFA answers the MFA challenge of a login with a TOTP or recovery
+	// code, starting the session Generate held back.
+	VerifyMFA(ctx context.Context, params *types.MFAParams, device *types.Device) (*types.TokenPair, error)
+	// AnswerMFA answers the MFA challenge of a relying party login the same
+	// way, returning it along with the methods the user authenticated with.
+	AnswerMFA(ctx context.Context, params *types.MFAParams) (*types.MFAChallenge, []string, error)
+	// Refresh issues user id a new access token for the session of the
+	// refresh token, seen from ip.
+	Refresh(ctx context.Context, id uint64, refreshToken, ip string) (string, error)
+	// Invalidate ends session of user id, or every one of their sessions
+	// when the access token named none.
+	Invalidate(ctx context.Context, id, session uint64) error
+	// Impersonate issues admin an access token of another user, carrying
+	// an act claim naming the admin. Every one is audited.
+	Impersonate(ctx context.Context, admin uint64, ip string, params *types.ImpersonateParams) (string, error)
 }
 
 type usecase struct {
-	store repository.ITokenStorage
-	cfg   *config.Config
+	store  repository.ITokenStorage
+	users  repository.IUserStorage
+	logins ILoginUsecase
+	mfa    repository.IMFAStorage
+	cfg    *config.Config
+	log    *zerolog.Logger
 }
 
-func NewTokenUsecase(store repository.ITokenStorage, cfg *config.Config) ITokenUsecase {
-	return &usecase{store, cfg}
+func NewTokenUsecase(
+	store repository.ITokenStorage,
+	users repository.IUserStorage,
+	logins ILoginUsecase,
+	mfa repository.IMFAStorage,
+	cfg *config.Config,
+	log *zerolog.Logger,
+) ITokenUsecase {
+	return &usecase{store, users, logins, mfa, cfg, log}
 }
 
-func (u *usecase) Generate(ctx context.Context, email string) (string, string, error) {
-	id, err := u.store.GetUserID(ctx, email)
+func (u *usecase) Authenticate(
+	ctx context.Context,
+	identity *types.Identity,
+	device *types.Device,
+) (uint64, error) {
+	if device == nil {
+		device = new(types.Device)
+	}
+	attempt := &types.LoginAttempt{
+		Email:     identity.Email,
+		Provider:  identity.Provider,
+		IP:        device.IP,
+		UserAgent: device.UserAgent,
+	}
+	id, err := u.authenticate(ctx, identity)
+	if err != nil {
+		reason, ok := refusal(err)
+		if !ok {
+			reason = reasonInternalError
+		}
+		attempt.Reason = reason
+	} else {
+		attempt.UserID = id
+		attempt.Success = true
+	}
+	u.logins.Record(ctx, attempt)
+	return id, err
+}
+
+func (u *usecase) authenticate(ctx context.Context, identity *types.Identity) (uint64, error) {
+	if err := u.authorize(identity); err != nil {
+		return 0, err
+	}
+	email := identity.Email
+	id, err := u.users.GetUserID(ctx, email)
 	if err != nil {
 		if errors.Is(repository.ErrNoRow, err) {
-			return "", "", &Error{
+			return 0, &Error{
 				Code:    http.StatusNotFound,
 				Message: msgUserNotRegistered,
+				Errors: []DomainError{{
+					Reason:   reasonUserNotRegistered,
+					Message:  msgUserNotRegistered,
+					Location: "token",
+				}},
 			}
 		}
-		return "", "", fmt.Errorf("fetch user id by email of %s: %w", email, err)
+		return 0, fmt.Errorf("fetch user id by email of %s: %w", email, err)
+	}
+	// A stale roster entry is no reason to refuse a login, so failures are
+	// only logged.
+	if identity.Provider == types.ProviderGoogle {
+		if err := u.users.SyncProfile(ctx, id, identity); err != nil {
+			u.log.Error().Err(err).Uint64("id", id).Msg("syncing google profile")
+		}
 	}
+	return id, nil
+}
+
+func (u *usecase) Generate(
+	ctx context.Context,
+	identity *types.Identity,
+	device *types.Device,
+) (*types.TokenPair, error) {
+	if device == nil {
+		device = new(types.Device)
+	}
+	id, err := u.Authenticate(ctx, identity, device)
+	if err != nil {
+		return nil, err
+	}
+	token, err := u.Challenge(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		return &types.TokenPair{MFAToken: token}, nil
+	}
+	return u.startSession(ctx, id, device, nil)
+}
+
+// startSession issues a refresh token and the first access token of a
+// session, which every access token refreshed from it carries amr of.
+func (u *usecase) startSession(
+	ctx context.Context,
+	id uint64,
+	device *types.Device,
+	amr []string,
+) (*types.TokenPair, error) {
+	// two logins within a second would sign the same token otherwise
+	jti, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(u.cfg.JWT.RefreshTTL) * time.Minute)
 	refresh := jwt.NewWithClaims(
 		jwt.SigningMethodHS512,
 		jwt.RegisteredClaims{
-			Subject:  fmt.Sprint(id),
-			IssuedAt: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(
-				time.Now().
-					UTC().
-					Add(time.Duration(u.cfg.JWT.RefreshTTL) * time.Minute),
-			),
-		},
-	)
-	access := jwt.NewWithClaims(
-		jwt.SigningMethodHS512,
-		jwt.RegisteredClaims{
-			Subject: fmt.Sprint(id),
-			ExpiresAt: jwt.NewNumericDate(time.Now().
-				UTC().
-				Add(time.Duration(u.cfg.JWT.AccessTTL) * time.Minute)),
+			ID:        jti,
+			Subject:   fmt.Sprint(id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	)
 	refreshToken, err := refresh.SignedString([]byte(u.cfg.JWT.Key))
 	if err != nil {
-		return "", "", fmt.Errorf("signing refresh token: %w", err)
+		return nil, fmt.Errorf("signing refresh token: %w", err)
 	}
-	accessToken, err := access.SignedString([]byte(u.cfg.JWT.Key))
+	groups, err := u.users.GetGroupNames(ctx, id)
 	if err != nil {
-		return "", "", fmt.Errorf("signing access token: %w", err)
+		return nil, fmt.Errorf("fetch group names of user %d: %w", id, err)
 	}
-	if err = u.store.CreateRefreshToken(ctx, email, refreshToken); err != nil {
-		return "", "", err
+	session, err := u.store.CreateRefreshToken(ctx, &types.Session{
+		UserID:    id,
+		Token:     refreshToken,
+		UserAgent: device.UserAgent,
+		IP:        device.IP,
+		AMR:       amr,
+		Groups:    groups,
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := u.signAccess(id, session, amr, groups)
+	if err != nil {
+		return nil, err
 	}
-	return refreshToken, accessToken, nil
+	return &types.TokenPair{RefreshToken: refreshToken, AccessToken: accessToken}, nil
 }
 
-func (u *usecase) Refresh(ctx context.Context, id uint64) (string, error) {
-	refreshToken, err := u.store.GetRefreshTokenByID(ctx, id)
+func (u *usecase) Refresh(ctx context.Context, id uint64, refreshToken, ip string) (string, error) {
+	session, err := u.store.GetSessionByToken(ctx, refreshToken)
 	if err != nil {
 		if errors.Is(err, repository.ErrNoRow) {
-			return "", Error{Code: http.StatusUnauthorized}
+			return "", &Error{Code: http.StatusUnauthorized}
 		}
-		return "", fmt.Errorf("fetching refresh token: %w", err)
+		return "", fmt.Errorf("fetching session: %w", err)
+	}
+	if session.UserID != id {
+		return "", &Error{Code: http.StatusUnauthorized}
 	}
-
 	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(u.cfg.JWT.Key), err
+		return []byte(u.cfg.JWT.Key), nil
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			err = u.store.DeleteRefreshToken(ctx, id)
-			if err != err {
-				return "", fmt.Errorf("deleting refresh token: %w", err)
+			if err := u.store.DeleteSession(ctx, id, session.ID); err != nil &&
+				!errors.Is(err, repository.ErrNoRowAffected) {
+				return "", fmt.Errorf("deleting expired session: %w", err)
 			}
 		}
 		return "", fmt.Errorf("parsing jwt token: %w", err)
 	}
 	if !token.Valid {
-		return "", Error{Code: http.StatusUnauthorized}
+		return "", &Error{Code: http.StatusUnauthorized}
+	}
+	// Group names are picked up again on every refresh, those the session
+	// last carried stand in while user-service is unreachable.
+	groups, err := u.users.GetGroupNames(ctx, id)
+	if err != nil {
+		if session.Groups == nil {
+			return "", fmt.Errorf("fetch group names of user %d: %w", id, err)
+		}
+		u.log.Warn().
+			Err(err).
+			Uint64("user_id", id).
+			Msg("refreshing with the last group names of the session")
+		groups = session.Groups
+	}
+	if err := u.store.TouchSession(ctx, session.ID, ip, groups); err != nil {
+		return "", err
 	}
-	sub, err := token.Claims.GetSubject()
+	return u.signAccess(id, session.ID, session.AMR, groups)
+}
+
+// signAccess issues an access token of the session carrying groups.
+func (u *usecase) signAccess(id, session uint64, amr, groups []string) (string, error) {
+	access := jwt.NewWithClaims(
+		jwt.SigningMethodHS512,
+		types.AccessClaims{
+			Groups:  groups,
+			Session: session,
+			AMR:     amr,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject: fmt.Sprint(id),
+				ExpiresAt: jwt.NewNumericDate(time.Now().
+					UTC().
+					Add(time.Duration(u.cfg.JWT.AccessTTL) * time.Minute)),
+			},
+		},
+	)
+	accessToken, err := access.SignedString([]byte(u.cfg.JWT.Key))
 	if err != nil {
-		return "", fmt.Errorf("getting jwt token subject: %w")
+		return "", fmt.Errorf("signing access token: %w", err)
+	}
+	return accessToken, nil
+}
+
+func (u *usecase) Invalidate(ctx context.Context, id, session uint64) error {
+	if session == 0 {
+		return u.store.DeleteSessions(ctx, id)
+	}
+	if err := u.store.DeleteSession(ctx, id, session); err != nil &&
+		!errors.Is(err, repository.ErrNoRowAffected) {
+		return err
+	}
+	return nil
+}
+
+const defaultImpersonationTTL = 15 * time.Minute
+
+func (u *usecase) Impersonate(
+	ctx context.Context,
+	admin uint64,
+	ip string,
+	params *types.ImpersonateParams,
+) (string, error) {
+	if err := authorizeAdmin(ctx, u.users, u.cfg, admin); err != nil {
+		return "", err
 	}
-	tokenID, err := strconv.ParseUint(sub, 10, 64)
+	if _, err := u.users.GetUser(ctx, params.UserID); err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return "", &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotRegistered,
+			}
+		}
+		return "", fmt.Errorf("fetch user %d: %w", params.UserID, err)
+	}
+	groups, err := u.users.GetGroupNames(ctx, params.UserID)
 	if err != nil {
-		return "", fmt.Errorf("parsing jwt subject of %s to uint64: %w", tokenID, err)
+		return "", fmt.Errorf("fetch group names of user %d: %w", params.UserID, err)
 	}
-	if id != tokenID {
-		return "", Error{Code: http.StatusUnauthorized}
+	// Admins acting as one another would blur who did what, and covers
+	// admins impersonating themselves too.
+	if isAdmin(u.cfg, groups) {
+		return "", &Error{
+			Code:    http.StatusForbidden,
+			Message: msgImpersonateAdmin,
+		}
+	}
+	ttl := defaultImpersonationTTL
+	if u.cfg.Admin.ImpersonationTTL > 0 {
+		ttl = time.Duration(u.cfg.Admin.ImpersonationTTL) * time.Minute
+	}
+	now := time.Now().UTC()
+	// The audit record comes first, no token is handed out unrecorded.
+	if err := u.store.CreateImpersonation(ctx, &types.Impersonation{
+		AdminID:   admin,
+		UserID:    params.UserID,
+		Reason:    params.Reason,
+		IP:        ip,
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		return "", err
 	}
 	access := jwt.NewWithClaims(
 		jwt.SigningMethodHS512,
-		jwt.RegisteredClaims{
-			Subject: fmt.Sprint(id),
-			ExpiresAt: jwt.NewNumericDate(time.Now().
-				UTC().
-				Add(time.Duration(u.cfg.JWT.AccessTTL) * time.Minute)),
+		types.AccessClaims{
+			Groups: groups,
+			Act:    &types.Actor{Subject: fmt.Sprint(admin)},
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   fmt.Sprint(params.UserID),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
 		},
 	)
 	accessToken, err := access.SignedString([]byte(u.cfg.JWT.Key))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("signing impersonation token: %w", err)
 	}
+	u.log.Info().
+		Uint64("admin", admin).
+		Uint64("user", params.UserID).
+		Str("reason", params.Reason).
+		Msg("impersonation token issued")
 	return accessToken, nil
 }
 
-func (u *usecase) Invalidate(ctx context.Context, id uint64) error {
-	return u.store.DeleteRefreshToken(ctx, id)
+// authorizeAdmin refuses user id unless they are currently an admin,
+// whatever their access token claims.
+func authorizeAdmin(ctx context.Context, users repository.IUserStorage, cfg *config.Config, id uint64) error {
+	groups, err := users.GetGroupNames(ctx, id)
+	if err != nil {
+		return fmt.Errorf("fetch group names of user %d: %w", id, err)
+	}
+	if !isAdmin(cfg, groups) {
+		return &Error{
+			Code:    http.StatusForbidden,
+			Message: msgNotAdmin,
+		}
+	}
+	return nil
+}
+
+func isAdmin(cfg *config.Config, groups []string) bool {
+	for _, group := range groups {
+		if slices.Contains(cfg.Admin.Groups, group) {
+			return true
+		}
+	}
+	return false
 }
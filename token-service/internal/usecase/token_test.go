@@ -0,0 +1,240 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Lab-ICN/backend/token-service/internal/config"
+	"github.com/Lab-ICN/backend/token-service/internal/repository"
+	"github.com/Lab-ICN/backend/token-service/internal/types"
+	"github.com/Lab-ICN/backend/token-service/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig() *config.Config {
+	cfg := new(config.Config)
+	cfg.JWT.Key = "secret"
+	cfg.JWT.AccessTTL = 5
+	cfg.JWT.RefreshTTL = 60
+	return cfg
+}
+
+func newUsecase() usecase.ITokenUsecase {
+	return newUsecaseWith(newConfig())
+}
+
+func newUsecaseWith(cfg *config.Config) usecase.ITokenUsecase {
+	log := zerolog.Nop()
+	return usecase.NewTokenUsecase(
+		repository.NewTokenFake(),
+		newUsers(),
+		newLogins(cfg),
+		repository.NewMFAFake(),
+		cfg,
+		&log,
+	)
+}
+
+func newUsers() repository.IUserStorage {
+	return repository.NewUserFake(
+		map[string]uint64{
+			"test@example.com":   1,
+			"admin@example.com":  2,
+			"admin2@example.com": 3,
+		},
+		map[uint64][]string{
+			1: {"networking"},
+			2: {"admin"},
+			3: {"admin", "networking"},
+		},
+	)
+}
+
+func newLogins(cfg *config.Config) usecase.ILoginUsecase {
+	log := zerolog.Nop()
+	return usecase.NewLoginUsecase(
+		repository.NewLoginFake(),
+		newUsers(),
+		repository.NewEventFake(),
+		nil,
+		cfg,
+		&log,
+	)
+}
+
+func googleIdentity(email string) *types.Identity {
+	return &types.Identity{
+		Provider:      types.ProviderGoogle,
+		Email:         email,
+		EmailVerified: true,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	tokens, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.MFAToken)
+}
+
+func TestGenerateGroupClaims(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	tokens, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(tokens.AccessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"networking"}, claims.Groups)
+}
+
+func TestGenerateUnregistered(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	_, err := u.Generate(ctx, googleIdentity("unknown@example.com"), nil)
+	uscErr := new(usecase.Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusNotFound, uscErr.Code)
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	tokens, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	access, err := u.Refresh(ctx, 1, tokens.RefreshToken, "127.0.0.1")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, access)
+	_, err = u.Refresh(ctx, 2, tokens.RefreshToken, "127.0.0.1")
+	assert.NotNil(t, err)
+}
+
+// downUsers fails to fetch group names once down, as when user-service is
+// unreachable.
+type downUsers struct {
+	repository.IUserStorage
+	down bool
+}
+
+func (d *downUsers) GetGroupNames(ctx context.Context, id uint64) ([]string, error) {
+	if d.down {
+		return nil, errors.New("user-service unreachable")
+	}
+	return d.IUserStorage.GetGroupNames(ctx, id)
+}
+
+func TestRefreshUsersDown(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	cfg := newConfig()
+	users := &downUsers{IUserStorage: newUsers()}
+	u := usecase.NewTokenUsecase(
+		repository.NewTokenFake(),
+		users,
+		newLogins(cfg),
+		repository.NewMFAFake(),
+		cfg,
+		&log,
+	)
+	tokens, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+
+	users.down = true
+	access, err := u.Refresh(ctx, 1, tokens.RefreshToken, "127.0.0.1")
+	assert.Nil(t, err, "refresh keeps working while user-service is down")
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(access, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"networking"}, claims.Groups, "the last groups of the session are carried")
+}
+
+func TestRefreshInvalidated(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	tokens, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, u.Invalidate(ctx, 1, 0))
+	_, err = u.Refresh(ctx, 1, tokens.RefreshToken, "127.0.0.1")
+	assert.NotNil(t, err)
+}
+
+func TestInvalidateOneSession(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	laptop, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	phone, err := u.Generate(ctx, googleIdentity("test@example.com"), nil)
+	assert.Nil(t, err)
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(phone.AccessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, claims.Session)
+
+	assert.Nil(t, u.Invalidate(ctx, 1, claims.Session))
+	_, err = u.Refresh(ctx, 1, phone.RefreshToken, "127.0.0.1")
+	assert.NotNil(t, err)
+	_, err = u.Refresh(ctx, 1, laptop.RefreshToken, "127.0.0.1")
+	assert.Nil(t, err)
+}
+
+func TestImpersonate(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	u := newUsecaseWith(cfg)
+	access, err := u.Impersonate(ctx, 2, "127.0.0.1", &types.ImpersonateParams{
+		UserID: 1,
+		Reason: "profile looks wrong",
+	})
+	assert.Nil(t, err)
+	claims := new(types.AccessClaims)
+	_, err = jwt.ParseWithClaims(access, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, []string{"networking"}, claims.Groups)
+	assert.Equal(t, "2", claims.Act.Subject)
+}
+
+func TestImpersonateRefused(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig()
+	cfg.Admin.Groups = []string{"admin"}
+	u := newUsecaseWith(cfg)
+	tests := []struct {
+		name  string
+		admin uint64
+		user  uint64
+		code  int
+	}{
+		{"not an admin", 1, 2, http.StatusForbidden},
+		{"another admin", 2, 3, http.StatusForbidden},
+		{"themselves", 2, 2, http.StatusForbidden},
+		{"unknown user", 2, 42, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Impersonate(ctx, tt.admin, "127.0.0.1", &types.ImpersonateParams{
+				UserID: tt.user,
+				Reason: "testing",
+			})
+			uscErr := new(usecase.Error)
+			assert.True(t, errors.As(err, &uscErr))
+			assert.Equal(t, tt.code, uscErr.Code)
+		})
+	}
+}
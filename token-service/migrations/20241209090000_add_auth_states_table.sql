@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE auth_states (
+  "state" VARCHAR(64) PRIMARY KEY,
+  "provider" VARCHAR(64) NOT NULL,
+  "nonce" VARCHAR(64) NOT NULL,
+  "verifier" VARCHAR(128) NOT NULL,
+  "redirect" TEXT NOT NULL,
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX auth_states_expires_at_idx ON auth_states ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE auth_states;
+
+-- +goose StatementEnd
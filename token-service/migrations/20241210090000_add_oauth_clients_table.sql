@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oauth_clients (
+  "id" VARCHAR(64) PRIMARY KEY,
+  "name" VARCHAR(255) NOT NULL,
+  "secret_hash" TEXT NOT NULL,
+  "scopes" TEXT[] NOT NULL DEFAULT '{}',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oauth_clients;
+
+-- +goose StatementEnd
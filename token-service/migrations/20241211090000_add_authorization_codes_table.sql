@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE oauth_clients ADD COLUMN "redirect_uris" TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE auth_states ADD COLUMN "authorization" JSONB;
+
+CREATE TABLE authorization_codes (
+  "code" VARCHAR(64) PRIMARY KEY,
+  "client_id" VARCHAR(64) NOT NULL REFERENCES oauth_clients ("id") ON DELETE CASCADE,
+  "user_id" BIGINT NOT NULL,
+  "redirect_uri" TEXT NOT NULL,
+  "scope" TEXT NOT NULL,
+  "nonce" TEXT NOT NULL DEFAULT '',
+  "challenge" VARCHAR(128) NOT NULL DEFAULT '',
+  "auth_time" TIMESTAMP NOT NULL,
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX authorization_codes_expires_at_idx ON authorization_codes ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE authorization_codes;
+
+ALTER TABLE auth_states DROP COLUMN "authorization";
+
+ALTER TABLE oauth_clients DROP COLUMN "redirect_uris";
+
+-- +goose StatementEnd
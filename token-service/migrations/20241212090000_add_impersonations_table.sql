@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE impersonations (
+  "id" BIGSERIAL PRIMARY KEY,
+  "admin_id" BIGINT NOT NULL,
+  "user_id" BIGINT NOT NULL,
+  "reason" TEXT NOT NULL,
+  "ip" VARCHAR(45) NOT NULL DEFAULT '',
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX impersonations_admin_id_idx ON impersonations ("admin_id");
+
+CREATE INDEX impersonations_user_id_idx ON impersonations ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE impersonations;
+
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP CONSTRAINT refresh_tokens_pkey;
+
+ALTER TABLE refresh_tokens ADD COLUMN "id" BIGSERIAL PRIMARY KEY;
+
+ALTER TABLE refresh_tokens ADD COLUMN "user_agent" TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE refresh_tokens ADD COLUMN "ip" VARCHAR(45) NOT NULL DEFAULT '';
+
+ALTER TABLE refresh_tokens ADD COLUMN "last_used_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- tokens from before sessions were tracked have no known expiry
+ALTER TABLE refresh_tokens ADD COLUMN "expires_at" TIMESTAMP;
+
+UPDATE refresh_tokens SET last_used_at = updated_at;
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens ("user_id");
+
+CREATE UNIQUE INDEX refresh_tokens_token_idx ON refresh_tokens ("token");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM refresh_tokens older
+USING refresh_tokens newer
+WHERE older.user_id = newer.user_id AND older.id < newer.id;
+
+DROP INDEX refresh_tokens_token_idx;
+
+DROP INDEX refresh_tokens_user_id_idx;
+
+ALTER TABLE refresh_tokens
+  DROP COLUMN "id",
+  DROP COLUMN "user_agent",
+  DROP COLUMN "ip",
+  DROP COLUMN "last_used_at",
+  DROP COLUMN "expires_at";
+
+ALTER TABLE refresh_tokens ADD PRIMARY KEY ("user_id");
+
+-- +goose StatementEnd
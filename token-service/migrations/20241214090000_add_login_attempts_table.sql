@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE login_attempts (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" BIGINT,
+  "email" VARCHAR(255) NOT NULL DEFAULT '',
+  "provider" VARCHAR(64) NOT NULL,
+  "ip" VARCHAR(45) NOT NULL DEFAULT '',
+  "user_agent" TEXT NOT NULL DEFAULT '',
+  "device" VARCHAR(64) NOT NULL DEFAULT '',
+  "country" CHAR(2) NOT NULL DEFAULT '',
+  "success" BOOLEAN NOT NULL,
+  "reason" VARCHAR(64) NOT NULL DEFAULT '',
+  "new_device" BOOLEAN NOT NULL DEFAULT FALSE,
+  "unusual_country" BOOLEAN NOT NULL DEFAULT FALSE,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX login_attempts_user_id_idx ON login_attempts ("user_id", "created_at");
+
+CREATE INDEX login_attempts_email_idx ON login_attempts ("email", "created_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rate_limits (
+  "key" VARCHAR(320) PRIMARY KEY,
+  "count" INTEGER NOT NULL,
+  "reset_at" TIMESTAMP NOT NULL
+);
+
+CREATE INDEX rate_limits_reset_at_idx ON rate_limits ("reset_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rate_limits;
+
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE mfa_factors (
+  "user_id" BIGINT PRIMARY KEY,
+  "secret" VARCHAR(64),
+  "pending_secret" VARCHAR(64),
+  "last_step" BIGINT NOT NULL DEFAULT 0,
+  "confirmed_at" TIMESTAMP,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE mfa_recovery_codes (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" BIGINT NOT NULL REFERENCES mfa_factors ("user_id") ON DELETE CASCADE,
+  "code_hash" CHAR(64) NOT NULL,
+  "used_at" TIMESTAMP
+);
+
+CREATE INDEX mfa_recovery_codes_user_id_idx ON mfa_recovery_codes ("user_id");
+
+CREATE TABLE mfa_challenges (
+  "token_hash" CHAR(64) PRIMARY KEY,
+  "user_id" BIGINT NOT NULL,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX mfa_challenges_expires_at_idx ON mfa_challenges ("expires_at");
+
+-- authentication methods of the login that started the session, carried
+-- over to every access token refreshed from it
+ALTER TABLE refresh_tokens ADD COLUMN "amr" TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN "amr";
+
+DROP TABLE mfa_challenges;
+
+DROP TABLE mfa_recovery_codes;
+
+DROP TABLE mfa_factors;
+
+-- +goose StatementEnd
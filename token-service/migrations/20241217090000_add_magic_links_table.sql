@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE magic_links (
+  "token_hash" CHAR(64) PRIMARY KEY,
+  "email" VARCHAR(255) NOT NULL,
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX magic_links_expires_at_idx ON magic_links ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE magic_links;
+
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- groups stay NULL for sessions from before they were recorded.
+ALTER TABLE refresh_tokens ADD COLUMN "groups" TEXT[];
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN "groups";
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the relying party login an MFA challenge holds back, NULL for logins of
+-- the frontend
+ALTER TABLE mfa_challenges ADD COLUMN "authorization" JSONB;
+
+ALTER TABLE authorization_codes ADD COLUMN "amr" TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE authorization_codes DROP COLUMN "amr";
+
+ALTER TABLE mfa_challenges DROP COLUMN "authorization";
+
+-- +goose StatementEnd
@@ -2,23 +2,32 @@ package main
 
 import (
 	"context"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	stdlog "log"
+	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Lab-ICN/backend/user-service/grpc"
 	"github.com/Lab-ICN/backend/user-service/http"
 	"github.com/Lab-ICN/backend/user-service/internal/config"
 	_fiber "github.com/Lab-ICN/backend/user-service/internal/fiber"
+	_grpc "github.com/Lab-ICN/backend/user-service/internal/grpc"
 	"github.com/Lab-ICN/backend/user-service/internal/postgresql"
+	"github.com/Lab-ICN/backend/user-service/internal/scheduler"
 	"github.com/Lab-ICN/backend/user-service/repository"
 	"github.com/Lab-ICN/backend/user-service/usecase"
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/rs/zerolog"
 )
@@ -50,14 +59,66 @@ func main() {
 	api := r.Group("/backend")
 
 	store := repository.NewUserPostgreSQL(postgresql)
-	usecase := usecase.NewUserUsecase(store, &log)
-	http.RegisterHandlers(usecase, cfg, api, validate)
+	if cfg.Avatar.Backend == "" {
+		cfg.Avatar.Backend = backendFilesystem
+	}
+	objects, err := newObjectStorage(cfg)
+	if err != nil {
+		stdlog.Fatalf("Failed to create object storage: %v\n", err)
+	}
+	if cfg.Avatar.Backend == backendFilesystem {
+		api.Static("/v1/avatars", filepath.Join(cfg.Avatar.Directory, "avatars"))
+	}
+	var exchangeKey *rsa.PublicKey
+	if cfg.ExchangeKeyFile != "" {
+		exchangeKey, err = readPublicKey(cfg.ExchangeKeyFile)
+		if err != nil {
+			stdlog.Fatalf("Failed to read exchange key: %v\n", err)
+		}
+	}
+	userUsecase := usecase.NewUserUsecase(store, objects, &log)
+	http.RegisterHandlers(userUsecase, exchangeKey, cfg, api, validate)
+	applicationStore := repository.NewApplicationPostgreSQL(postgresql)
+	applicationUsecase := usecase.NewApplicationUsecase(applicationStore)
+	http.RegisterApplicationHandlers(applicationUsecase, cfg, api, validate)
+	groupStore := repository.NewGroupPostgreSQL(postgresql)
+	groupUsecase := usecase.NewGroupUsecase(groupStore)
+	http.RegisterGroupHandlers(groupUsecase, exchangeKey, cfg, api, validate)
+
+	interceptors := []_grpc.UnaryServerInterceptor{}
+	if cfg.GRPC.Token != "" {
+		interceptors = append(interceptors, grpc.TokenAuth(cfg.GRPC.Token))
+	}
+	g, err := _grpc.New(cfg, &log, interceptors...)
+	if err != nil {
+		stdlog.Fatalf("Failed to create grpc server: %v\n", err)
+	}
+	grpc.RegisterServer(userUsecase, groupUsecase, g, validate)
+	sched, err := scheduler.New(postgresql, userUsecase, cfg, &log)
+	if err != nil {
+		stdlog.Fatalf("Failed to create scheduler: %v\n", err)
+	}
+	schedCtx, stopSched := context.WithCancel(ctx)
 
 	go func() {
 		if err := r.Listen(fmt.Sprintf("%s:%d", cfg.Address, cfg.Port)); err != nil {
 			stdlog.Panicf("Server panicked: %v\n", err)
 		}
 	}()
+	// prefork children share the http port, the grpc port and the scheduler
+	// only run in the parent
+	if !fiber.IsChild() {
+		sched.Start(schedCtx)
+		go func() {
+			lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.GRPC.Address, cfg.GRPC.Port))
+			if err != nil {
+				stdlog.Panicf("Failed to listen grpc port: %v\n", err)
+			}
+			if err := g.Serve(lis); err != nil {
+				stdlog.Panicf("GRPC server panicked: %v\n", err)
+			}
+		}()
+	}
 
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
@@ -68,6 +129,13 @@ func main() {
 			return r.Shutdown()
 		},
 		func(ctx context.Context) error {
+			g.GracefulStop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			// the pool outlives the scheduler run in flight
+			stopSched()
+			sched.Wait()
 			postgresql.Close()
 			return nil
 		},
@@ -79,6 +147,55 @@ func main() {
 	stdlog.Println("Gracefully shutdown...")
 }
 
+const (
+	backendFilesystem = "filesystem"
+	backendS3         = "s3"
+)
+
+// readPublicKey reads a PEM encoded RSA public key, in either PKIX or
+// PKCS #1 form.
+func readPublicKey(path string) (*rsa.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is not rsa", path)
+	}
+	return rsaKey, nil
+}
+
+func newObjectStorage(cfg *config.Config) (repository.IObjectStorage, error) {
+	switch cfg.Avatar.Backend {
+	case backendFilesystem:
+		return repository.NewObjectFilesystem(cfg.Avatar.Directory, cfg.Avatar.BaseURL), nil
+	case backendS3:
+		return repository.NewObjectS3(
+			cfg.Avatar.S3.Endpoint,
+			cfg.Avatar.S3.AccessKey,
+			cfg.Avatar.S3.SecretKey,
+			cfg.Avatar.S3.Region,
+			cfg.Avatar.S3.Bucket,
+			cfg.Avatar.BaseURL,
+			cfg.Avatar.S3.UseSSL,
+		)
+	default:
+		return nil, fmt.Errorf("unknown avatar backend %s", cfg.Avatar.Backend)
+	}
+}
+
 func gracefulShutdown(
 	ctx context.Context,
 	timeout time.Duration,
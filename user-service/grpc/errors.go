@@ -0,0 +1,8 @@
+package grpc
+
+const (
+	msgInvalidBearer        = "bearer metadata malformed"
+	msgMissingAuthorization = "missing authorization metadata"
+	msgIncorrectToken       = "incorrect token"
+	msgInvalidEmail         = "email missing or malformed"
+)
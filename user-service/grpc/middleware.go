@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	keyAuthorization = "authorization"
+)
+
+func TokenAuth(token string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(keyAuthorization)
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, msgMissingAuthorization)
+		}
+		bearer := strings.SplitN(values[0], " ", 2)
+		if bearer[0] != "Bearer" || len(bearer) != 2 {
+			return nil, status.Error(codes.Unauthenticated, msgInvalidBearer)
+		}
+		if subtle.ConstantTimeCompare([]byte(bearer[1]), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, msgIncorrectToken)
+		}
+		return handler(ctx, req)
+	}
+}
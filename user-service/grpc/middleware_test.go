@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenAuth(t *testing.T) {
+	interceptor := TokenAuth("secret")
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	for name, tc := range map[string]struct {
+		md   metadata.MD
+		code codes.Code
+	}{
+		"no metadata":      {nil, codes.Unauthenticated},
+		"no authorization": {metadata.Pairs("x-other", "secret"), codes.Unauthenticated},
+		"not a bearer":     {metadata.Pairs(keyAuthorization, "Basic secret"), codes.Unauthenticated},
+		"wrong token":      {metadata.Pairs(keyAuthorization, "Bearer wrong"), codes.Unauthenticated},
+		"right token":      {metadata.Pairs(keyAuthorization, "Bearer secret"), codes.OK},
+	} {
+		ctx := context.Background()
+		if tc.md != nil {
+			ctx = metadata.NewIncomingContext(ctx, tc.md)
+		}
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}, handler)
+		assert.Equal(t, tc.code, status.Code(err), name)
+		if tc.code == codes.OK {
+			assert.Equal(t, "ok", resp, name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+
+	userv1 "github.com/Lab-ICN/backend/user-service/pb/user/v1"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Server struct {
+	userv1.UnimplementedUserServiceServer
+	usecase  usecase.IUserUsecase
+	groups   usecase.IGroupUsecase
+	validate *validator.Validate
+}
+
+func RegisterServer(
+	usecase usecase.IUserUsecase,
+	groups usecase.IGroupUsecase,
+	s *grpc.Server,
+	validate *validator.Validate,
+) {
+	userv1.RegisterUserServiceServer(s, &Server{
+		usecase:  usecase,
+		groups:   groups,
+		validate: validate,
+	})
+}
+
+func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	user, err := s.usecase.Fetch(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toProto(user), nil
+}
+
+func (s *Server) GetUserByEmail(
+	ctx context.Context,
+	req *userv1.GetUserByEmailRequest,
+) (*userv1.User, error) {
+	if err := s.validate.Var(req.GetEmail(), "required,email"); err != nil {
+		return nil, status.Error(codes.InvalidArgument, msgInvalidEmail)
+	}
+	user, err := s.usecase.FetchByEmail(ctx, req.GetEmail())
+	if err != nil {
+		return nil, err
+	}
+	return toProto(user), nil
+}
+
+func (s *Server) ListUsers(
+	ctx context.Context,
+	req *userv1.ListUsersRequest,
+) (*userv1.ListUsersResponse, error) {
+	users, err := s.usecase.FetchList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.ListUsersResponse{Users: toProtos(users)}, nil
+}
+
+func (s *Server) BatchGetUsers(
+	ctx context.Context,
+	req *userv1.BatchGetUsersRequest,
+) (*userv1.BatchGetUsersResponse, error) {
+	params := &types.BatchGetUsersParams{IDs: req.GetIds(), Emails: req.GetEmails()}
+	if err := s.validate.Struct(params); err != nil {
+		return nil, status.Error(codes.InvalidArgument, msgInvalidEmail)
+	}
+	batch, err := s.usecase.FetchBatch(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.BatchGetUsersResponse{
+		Users:         toProtos(batch.Users),
+		MissingIds:    batch.Missing.IDs,
+		MissingEmails: batch.Missing.Emails,
+	}, nil
+}
+
+func (s *Server) ListUserGroups(
+	ctx context.Context,
+	req *userv1.ListUserGroupsRequest,
+) (*userv1.ListUserGroupsResponse, error) {
+	groups, err := s.groups.FetchByUser(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &userv1.ListUserGroupsResponse{Groups: make([]*userv1.Group, len(groups))}
+	for i, group := range groups {
+		resp.Groups[i] = &userv1.Group{
+			Id:   group.ID,
+			Name: group.Name,
+			Role: string(group.Role),
+		}
+	}
+	return resp, nil
+}
+
+func toProto(user types.User) *userv1.User {
+	proto := &userv1.User{
+		Id:                  user.ID,
+		Email:               user.Email,
+		Username:            user.Username,
+		Fullname:            user.Fullname,
+		IsMember:            user.IsMember,
+		Status:              string(user.Status),
+		InternshipStartDate: timestamppb.New(user.InternshipStartDate),
+	}
+	if user.InternshipEndDate != nil {
+		proto.InternshipEndDate = timestamppb.New(*user.InternshipEndDate)
+	}
+	return proto
+}
+
+func toProtos(users []types.User) []*userv1.User {
+	protos := make([]*userv1.User, len(users))
+	for i, user := range users {
+		protos[i] = toProto(user)
+	}
+	return protos
+}
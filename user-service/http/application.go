@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	keyStatus = "status"
+)
+
+type ApplicationHandler struct {
+	usecase  usecase.IApplicationUsecase
+	validate *validator.Validate
+}
+
+func RegisterApplicationHandlers(
+	usecase usecase.IApplicationUsecase,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := ApplicationHandler{usecase, validate}
+	v1 := r.Group("/v1/applications")
+	v1.Post("/", h.Post)
+	v1.Get("/", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "applications:read"), h.List)
+	v1.Post("/:id<int>/approve", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "applications:write"), h.Approve)
+	v1.Post("/:id<int>/reject", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "applications:write"), h.Reject)
+}
+
+func (h *ApplicationHandler) Post(c *fiber.Ctx) error {
+	payload := new(types.CreateApplicationParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidApplication,
+			Err:     err,
+		}
+	}
+	application, err := h.usecase.Apply(c.Context(), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(application)
+}
+
+func (h *ApplicationHandler) List(c *fiber.Ctx) error {
+	applications, err := h.usecase.FetchQueue(
+		c.Context(),
+		types.ApplicationStatus(c.Query(keyStatus)),
+	)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(applications)
+}
+
+func (h *ApplicationHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.usecase.Approve)
+}
+
+func (h *ApplicationHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.usecase.Reject)
+}
+
+func (h *ApplicationHandler) review(
+	c *fiber.Ctx,
+	apply func(context.Context, uint64, *types.ReviewApplicationParams) (types.Application, error),
+) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	payload := new(types.ReviewApplicationParams)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(payload); err != nil {
+			return &usecase.Error{
+				Code: http.StatusBadRequest,
+				Err:  err,
+			}
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusUnprocessableEntity,
+			Err:  err,
+		}
+	}
+	application, err := apply(c.Context(), uint64(_id), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(application)
+}
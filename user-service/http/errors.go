@@ -3,8 +3,21 @@ package http
 const (
 	msgMustCSV              = "file must be in csv"
 	msgInvalidBearer        = "bearer header malformed"
+	msgInvalidToken         = "access token is invalid or expired"
 	msgMissingSub           = "jwt missing sub"
 	msgMissingAuthorization = "missing authorization header"
 	msgMissingAttachment    = "attachment file missing"
 	msgIncorrectApiKey      = "incorrect api key"
+	msgInvalidEmail         = "email missing or malformed"
+	msgInvalidApplication   = "application missing or has malformed fields"
+	msgInvalidGroup         = "group missing or has malformed fields"
+	msgInvalidRole          = "role must be lead or member"
+	msgAvatarTooLarge       = "avatar must not exceed %d bytes"
+	msgInvalidProfile       = "profile has malformed fields"
+	msgInvalidGoogleProfile = "google profile has malformed fields"
+	msgClientToken          = "client tokens do not act for a user"
+	msgWrongAudience        = "access token is issued for another audience"
+	msgExchangedToken       = "exchanged tokens are not taken here"
+	msgNotClientToken       = "bearer token is not a client token"
+	msgMissingScope         = "token lacks scope %s"
 )
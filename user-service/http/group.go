@@ -0,0 +1,184 @@
+package http
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupHandler struct {
+	usecase  usecase.IGroupUsecase
+	validate *validator.Validate
+}
+
+func RegisterGroupHandlers(
+	usecase usecase.IGroupUsecase,
+	exchanged *rsa.PublicKey,
+	cfg *config.Config,
+	r fiber.Router,
+	validate *validator.Validate,
+) {
+	h := GroupHandler{usecase, validate}
+	v1 := r.Group("/v1/groups")
+	v1.Get("/", BearerAuth(cfg.JwtKey, exchanged, scopeGroupsRead), h.List)
+	v1.Get("/:id<int>", BearerAuth(cfg.JwtKey, exchanged, scopeGroupsRead), h.Get)
+	v1.Post("/", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:write"), h.Post)
+	v1.Put("/:id<int>", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:write"), h.Put)
+	v1.Delete("/:id<int>", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:write"), h.Delete)
+	v1.Put("/:id<int>/members/:userId<int>", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:write"), h.PutMember)
+	v1.Delete("/:id<int>/members/:userId<int>", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:write"), h.DeleteMember)
+	users := r.Group("/v1/users")
+	users.Get("/self/groups", BearerAuth(cfg.JwtKey, exchanged, scopeGroupsRead), h.ListSelf)
+	users.Get("/:id<int>/groups", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "groups:read"), h.ListByUser)
+}
+
+func (h *GroupHandler) List(c *fiber.Ctx) error {
+	groups, err := h.usecase.FetchList(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(groups)
+}
+
+func (h *GroupHandler) Get(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	group, err := h.usecase.Fetch(c.Context(), uint64(_id))
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(group)
+}
+
+func (h *GroupHandler) Post(c *fiber.Ctx) error {
+	payload, err := h.parse(c)
+	if err != nil {
+		return err
+	}
+	group, err := h.usecase.Create(c.Context(), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(group)
+}
+
+func (h *GroupHandler) Put(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	payload, err := h.parse(c)
+	if err != nil {
+		return err
+	}
+	group, err := h.usecase.Update(c.Context(), uint64(_id), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(group)
+}
+
+func (h *GroupHandler) Delete(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	if err := h.usecase.Delete(c.Context(), uint64(_id)); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *GroupHandler) PutMember(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	_userID, err := c.ParamsInt("userId")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	payload := new(types.GroupMemberParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidRole,
+			Err:     err,
+		}
+	}
+	if err := h.usecase.PutMember(c.Context(), uint64(_id), uint64(_userID), payload.Role); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *GroupHandler) DeleteMember(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	_userID, err := c.ParamsInt("userId")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	if err := h.usecase.DeleteMember(c.Context(), uint64(_id), uint64(_userID)); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *GroupHandler) ListSelf(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return fmt.Errorf("assert string of %s to uint64", c.Locals(keyClientID))
+	}
+	groups, err := h.usecase.FetchByUser(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(groups)
+}
+
+func (h *GroupHandler) ListByUser(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	groups, err := h.usecase.FetchByUser(c.Context(), uint64(_id))
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(groups)
+}
+
+func (h *GroupHandler) parse(c *fiber.Ctx) (*types.GroupParams, error) {
+	payload := new(types.GroupParams)
+	if err := c.BodyParser(payload); err != nil {
+		return nil, &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return nil, &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidGroup,
+			Err:     err,
+		}
+	}
+	return payload, nil
+}
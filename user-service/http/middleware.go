@@ -2,9 +2,12 @@ package http
 
 import (
 	"bytes"
+	"crypto/rsa"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -15,9 +18,20 @@ import (
 
 const (
 	keyClientID = "id"
+	// audience is the aud claim of client and exchanged tokens for
+	// user-service
+	audience = "user-service"
 )
 
-func BearerAuth(key string) func(c *fiber.Ctx) error {
+// errNoExchangeKey fails parsing exchanged tokens where there is no key to
+// check them with.
+var errNoExchangeKey = errors.New("no key to verify exchanged tokens")
+
+// BearerAuth takes access tokens of users. Tokens token-service exchanged
+// for an audience are signed with its OIDC key, whose public half is
+// exchanged, and only pass when they are for user-service and granted
+// scope. Routes without a scope, or without exchanged, refuse them.
+func BearerAuth(key string, exchanged *rsa.PublicKey, scope string) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		authorization := c.Get(fiber.HeaderAuthorization)
 		bearer := strings.SplitN(authorization, " ", 2)
@@ -28,14 +42,43 @@ func BearerAuth(key string) func(c *fiber.Ctx) error {
 			}
 		}
 		token, err := jwt.Parse(bearer[1], func(token *jwt.Token) (interface{}, error) {
-			return []byte(key), nil
-		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Name}))
+			// each kind of token is only checked with its own key
+			if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+				return []byte(key), nil
+			}
+			if exchanged == nil {
+				return nil, errNoExchangeKey
+			}
+			return exchanged, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Name, jwt.SigningMethodRS256.Name}))
 		if err != nil {
-			return &usecase.Error{Code: http.StatusUnauthorized, Message: err.Error(), Err: err}
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgInvalidToken, Err: err}
 		}
 		if !token.Valid {
 			return &usecase.Error{Code: http.StatusUnauthorized}
 		}
+		claims, _ := token.Claims.(jwt.MapClaims)
+		if claims["client_id"] != nil {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgClientToken}
+		}
+		aud, _ := claims.GetAudience()
+		if token.Method.Alg() == jwt.SigningMethodRS256.Alg() {
+			if !slices.Contains(aud, audience) {
+				return &usecase.Error{Code: http.StatusUnauthorized, Message: msgWrongAudience}
+			}
+			if scope == "" {
+				return &usecase.Error{Code: http.StatusForbidden, Message: msgExchangedToken}
+			}
+			granted, _ := claims["scope"].(string)
+			if !slices.Contains(strings.Fields(granted), scope) {
+				return &usecase.Error{
+					Code:    http.StatusForbidden,
+					Message: fmt.Sprintf(msgMissingScope, scope),
+				}
+			}
+		} else if len(aud) > 0 {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgWrongAudience}
+		}
 		sub, err := token.Claims.GetSubject()
 		if err != nil {
 			return &usecase.Error{Code: http.StatusBadRequest, Message: msgMissingSub, Err: err}
@@ -49,6 +92,44 @@ func BearerAuth(key string) func(c *fiber.Ctx) error {
 	}
 }
 
+// ServiceAuth lets machine callers in, either with a client credentials token
+// token-service issued for user-service granted scope, or with the shared api
+// key.
+func ServiceAuth(jwtKey, apiKey, scope string) func(c *fiber.Ctx) error {
+	apiKeyAuth := ApiKeyAuth(apiKey)
+	return func(c *fiber.Ctx) error {
+		bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok {
+			return apiKeyAuth(c)
+		}
+		claims := new(clientClaims)
+		_, err := jwt.ParseWithClaims(bearer, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtKey), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Name}), jwt.WithAudience(audience))
+		if err != nil {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgInvalidToken, Err: err}
+		}
+		if claims.ClientID == "" {
+			return &usecase.Error{Code: http.StatusUnauthorized, Message: msgNotClientToken}
+		}
+		if !slices.Contains(strings.Fields(claims.Scope), scope) {
+			return &usecase.Error{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf(msgMissingScope, scope),
+			}
+		}
+		return c.Next()
+	}
+}
+
+// clientClaims are those of client credentials tokens issued by
+// token-service.
+type clientClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
 func ApiKeyAuth(key string) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		header := c.Get(fiber.HeaderAuthorization, "")
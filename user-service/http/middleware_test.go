@@ -0,0 +1,137 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_fiber "github.com/Lab-ICN/backend/user-service/internal/fiber"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// signClient signs a client credentials token the way token-service does.
+func signClient(t *testing.T, key, scope string, audience jwt.ClaimStrings) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, clientClaims{
+		ClientID: "seeder",
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "seeder",
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte(key))
+	assert.Nil(t, err)
+	return token
+}
+
+func TestServiceAuth(t *testing.T) {
+	log := zerolog.Nop()
+	apiKey := base64.StdEncoding.EncodeToString([]byte("api-key"))
+	app := fiber.New(fiber.Config{ErrorHandler: _fiber.NewErrorHandler(&log)})
+	app.Get("/users", ServiceAuth("secret", apiKey, "users:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	user, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.RegisteredClaims{
+		Subject:   "1",
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"granted scope", "Bearer " + signClient(t, "secret", "users:read users:write", jwt.ClaimStrings{audience}), http.StatusOK},
+		{"missing scope", "Bearer " + signClient(t, "secret", "users:write", jwt.ClaimStrings{audience}), http.StatusForbidden},
+		{"other audience", "Bearer " + signClient(t, "secret", "users:read", jwt.ClaimStrings{"calendar"}), http.StatusUnauthorized},
+		{"no audience", "Bearer " + signClient(t, "secret", "users:read", nil), http.StatusUnauthorized},
+		{"forged", "Bearer " + signClient(t, "forged", "users:read", jwt.ClaimStrings{audience}), http.StatusUnauthorized},
+		{"user token", "Bearer " + user, http.StatusUnauthorized},
+		{"api key", apiKey, http.StatusOK},
+		{"wrong api key", base64.StdEncoding.EncodeToString([]byte("guess")), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		if tc.authorization != "" {
+			req.Header.Set(fiber.HeaderAuthorization, tc.authorization)
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	log := zerolog.Nop()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	app := fiber.New(fiber.Config{ErrorHandler: _fiber.NewErrorHandler(&log)})
+	self := func(c *fiber.Ctx) error {
+		return c.JSON(c.Locals(keyClientID))
+	}
+	app.Get("/self", BearerAuth("secret", &key.PublicKey, "profile:read"), self)
+	app.Get("/unscoped", BearerAuth("secret", &key.PublicKey, ""), self)
+	app.Get("/keyless", BearerAuth("secret", nil, "profile:read"), self)
+	sign := func(audience jwt.ClaimStrings) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.RegisteredClaims{
+			Subject:   "1",
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}).SignedString([]byte("secret"))
+		assert.Nil(t, err)
+		return token
+	}
+	exchange := func(signer *rsa.PrivateKey, audience, scope string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub":   "1",
+			"aud":   audience,
+			"scope": scope,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}).SignedString(signer)
+		assert.Nil(t, err)
+		return token
+	}
+	for _, tc := range []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{"full token", "/self", sign(nil), http.StatusOK},
+		{"full token with an audience", "/self", sign(jwt.ClaimStrings{audience}), http.StatusUnauthorized},
+		{"client token", "/self", signClient(t, "secret", "users:read", jwt.ClaimStrings{audience}), http.StatusUnauthorized},
+		{"exchanged with the scope", "/self", exchange(key, audience, "directory:read profile:read"), http.StatusOK},
+		{"exchanged without the scope", "/self", exchange(key, audience, "groups:read"), http.StatusForbidden},
+		{"exchanged elsewhere", "/self", exchange(key, "calendar", "profile:read"), http.StatusUnauthorized},
+		{"exchanged for an unscoped route", "/unscoped", exchange(key, audience, "profile:read"), http.StatusForbidden},
+		{"exchanged without a key", "/keyless", exchange(key, audience, "profile:read"), http.StatusUnauthorized},
+		{"exchanged with another key", "/self", exchange(other, audience, "profile:read"), http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tc.token)
+		resp, err := app.Test(req)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/self", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer forged.token.value")
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	body := struct{ Message string }{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, msgInvalidToken, body.Message, "parser errors are not told")
+}
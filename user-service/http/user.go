@@ -1,6 +1,8 @@
 package http
 
 import (
+	"context"
+	"crypto/rsa"
 	"fmt"
 	"net/http"
 	"path/filepath"
@@ -14,25 +16,52 @@ import (
 )
 
 const (
-	keyFile = "attachment"
+	keyFile  = "attachment"
+	keyEmail = "email"
+	keyQuery = "q"
+	// defaultAvatarMaxSize applies when avatar.maxSize is unset
+	defaultAvatarMaxSize = 2 << 20
+)
+
+// Scopes tokens exchanged for user-service are granted to act for their
+// user.
+const (
+	scopeProfileRead   = "profile:read"
+	scopeProfileWrite  = "profile:write"
+	scopeDirectoryRead = "directory:read"
+	scopeGroupsRead    = "groups:read"
 )
 
 type Handler struct {
 	usecase  usecase.IUserUsecase
+	cfg      *config.Config
 	validate *validator.Validate
 }
 
 func RegisterHandlers(
 	usecase usecase.IUserUsecase,
+	exchanged *rsa.PublicKey,
 	cfg *config.Config,
 	r fiber.Router,
 	validate *validator.Validate,
 ) {
-	h := Handler{usecase, validate}
+	h := Handler{usecase, cfg, validate}
 	v1 := r.Group("/v1/users")
-	v1.Get("/self", BearerAuth(cfg.JwtKey), h.Get)
-	v1.Post("/", ApiKeyAuth(cfg.ApiKey), h.Post)
-	v1.Delete("/:id<int>", ApiKeyAuth(cfg.ApiKey), h.Delete)
+	v1.Get("/self", BearerAuth(cfg.JwtKey, exchanged, scopeProfileRead), h.Get)
+	v1.Put("/self/avatar", BearerAuth(cfg.JwtKey, exchanged, scopeProfileWrite), h.PutAvatar)
+	v1.Delete("/self/avatar", BearerAuth(cfg.JwtKey, exchanged, scopeProfileWrite), h.DeleteAvatar)
+	v1.Get("/self/profile", BearerAuth(cfg.JwtKey, exchanged, scopeProfileRead), h.GetProfile)
+	v1.Put("/self/profile", BearerAuth(cfg.JwtKey, exchanged, scopeProfileWrite), h.PutProfile)
+	r.Get("/v1/directory", h.Directory)
+	v1.Get("/search", BearerAuth(cfg.JwtKey, exchanged, scopeDirectoryRead), h.Search)
+	v1.Get("/", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:read"), h.GetByEmail)
+	v1.Post("/", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:write"), h.Post)
+	v1.Delete("/:id<int>", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:write"), h.Delete)
+	v1.Post("/:id<int>/promote", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:write"), h.Promote)
+	v1.Post("/:id<int>/graduate", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:write"), h.Graduate)
+	v1.Get("/:id<int>/history", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:read"), h.History)
+	v1.Put("/:id<int>/google", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:write"), h.SyncGoogle)
+	r.Post("/v1/users\\:batchGet", ServiceAuth(cfg.JwtKey, cfg.ApiKey, "users:read"), h.BatchGet)
 }
 
 func (h *Handler) Post(c *fiber.Ctx) error {
@@ -82,6 +111,208 @@ func (h *Handler) Get(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(user)
 }
 
+func (h *Handler) GetByEmail(c *fiber.Ctx) error {
+	email := c.Query(keyEmail)
+	if err := h.validate.Var(email, "required,email"); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusBadRequest,
+			Message: msgInvalidEmail,
+			Err:     err,
+		}
+	}
+	user, err := h.usecase.FetchByEmail(c.Context(), email)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(user)
+}
+
+func (h *Handler) BatchGet(c *fiber.Ctx) error {
+	payload := new(types.BatchGetUsersParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidEmail,
+			Err:     err,
+		}
+	}
+	batch, err := h.usecase.FetchBatch(c.Context(), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(batch)
+}
+
+func (h *Handler) PutAvatar(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return fmt.Errorf("assert string of %s to uint64", c.Locals(keyClientID))
+	}
+	filehead, err := c.FormFile(keyFile)
+	if err != nil {
+		return &usecase.Error{
+			Code:    http.StatusBadRequest,
+			Message: msgMissingAttachment,
+			Err:     err,
+		}
+	}
+	maxSize := h.cfg.Avatar.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultAvatarMaxSize
+	}
+	if filehead.Size > int64(maxSize) {
+		return &usecase.Error{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf(msgAvatarTooLarge, maxSize),
+		}
+	}
+	user, err := h.usecase.UploadAvatar(c.Context(), id, filehead)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(user)
+}
+
+func (h *Handler) DeleteAvatar(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return fmt.Errorf("assert string of %s to uint64", c.Locals(keyClientID))
+	}
+	if err := h.usecase.DeleteAvatar(c.Context(), id); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *Handler) GetProfile(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return fmt.Errorf("assert string of %s to uint64", c.Locals(keyClientID))
+	}
+	profile, err := h.usecase.FetchProfile(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(profile)
+}
+
+func (h *Handler) PutProfile(c *fiber.Ctx) error {
+	id, ok := c.Locals(keyClientID).(uint64)
+	if !ok {
+		return fmt.Errorf("assert string of %s to uint64", c.Locals(keyClientID))
+	}
+	payload := new(types.ProfileParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidProfile,
+			Err:     err,
+		}
+	}
+	profile, err := h.usecase.UpdateProfile(c.Context(), id, payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(profile)
+}
+
+func (h *Handler) SyncGoogle(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	payload := new(types.GoogleProfileParams)
+	if err := c.BodyParser(payload); err != nil {
+		return &usecase.Error{
+			Code: http.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	if err := h.validate.Struct(payload); err != nil {
+		return &usecase.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgInvalidGoogleProfile,
+			Err:     err,
+		}
+	}
+	if err := h.usecase.SyncGoogle(c.Context(), uint64(_id), payload); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *Handler) Directory(c *fiber.Ctx) error {
+	entries, err := h.usecase.FetchDirectory(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(entries)
+}
+
+func (h *Handler) Search(c *fiber.Ctx) error {
+	results, err := h.usecase.Search(c.Context(), c.Query(keyQuery))
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(results)
+}
+
+func (h *Handler) Promote(c *fiber.Ctx) error {
+	return h.transition(c, h.usecase.Promote)
+}
+
+func (h *Handler) Graduate(c *fiber.Ctx) error {
+	return h.transition(c, h.usecase.Graduate)
+}
+
+func (h *Handler) transition(
+	c *fiber.Ctx,
+	apply func(context.Context, uint64, *types.TransitionParams) (types.User, error),
+) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	payload := new(types.TransitionParams)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(payload); err != nil {
+			return &usecase.Error{
+				Code: http.StatusBadRequest,
+				Err:  err,
+			}
+		}
+	}
+	user, err := apply(c.Context(), uint64(_id), payload)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(user)
+}
+
+func (h *Handler) History(c *fiber.Ctx) error {
+	_id, err := c.ParamsInt("id")
+	if err != nil {
+		return &usecase.Error{Code: http.StatusUnprocessableEntity}
+	}
+	transitions, err := h.usecase.FetchHistory(c.Context(), uint64(_id))
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(transitions)
+}
+
 func (h *Handler) Delete(c *fiber.Ctx) error {
 	_id, err := c.ParamsInt("id")
 	if err != nil {
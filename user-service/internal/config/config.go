@@ -5,7 +5,15 @@ type Config struct {
 	JwtKey      string
 	ApiKey      string
 	host        `mapstructure:",squash"`
+	GRPC        grpc
+	Scheduler   scheduler
+	Avatar      avatar
 	Development bool
+
+	// ExchangeKeyFile is the PEM encoded RSA public key token-service
+	// publishes in its JWKS and signs the tokens it exchanges for
+	// user-service with. Exchanged tokens are refused without one.
+	ExchangeKeyFile string
 }
 
 type host struct {
@@ -19,3 +27,51 @@ type postgreSQL struct {
 	Database string
 	host     `mapstructure:",squash"`
 }
+
+// grpc serves the internal API. Outside development callers authenticate
+// with a certificate signed by ClientCAFile or with Token.
+type grpc struct {
+	host         `mapstructure:",squash"`
+	Token        string
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// Plaintext serves without TLS or a token, only honored in development
+	Plaintext bool
+}
+
+type scheduler struct {
+	// Interval between runs in minutes, zero disables the scheduler
+	Interval int
+	Rules    []SchedulerRule
+}
+
+type SchedulerRule struct {
+	// Status the rule applies to
+	Status string
+	// Action is either promote or graduate
+	Action string
+	// AfterMonths since the internship start date the action is due
+	AfterMonths int
+}
+
+type avatar struct {
+	// Backend is either filesystem, the default, or s3
+	Backend string
+	// MaxSize of an uploaded avatar in bytes, 2 MiB when unset
+	MaxSize int
+	// Directory the filesystem backend writes to
+	Directory string
+	// BaseURL avatars are publicly served under
+	BaseURL string
+	S3      s3
+}
+
+type s3 struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Region    string
+	Bucket    string
+	UseSSL    bool
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type UnaryServerInterceptor = grpc.UnaryServerInterceptor
+
+func New(
+	cfg *config.Config,
+	log *zerolog.Logger,
+	interceptors ...grpc.UnaryServerInterceptor,
+) (*grpc.Server, error) {
+	// The internal API lists every user, so it is never served to callers
+	// who do not authenticate, with a client certificate or the token, by
+	// accident. TLS alone only encrypts, and is as open as plaintext.
+	authenticated := cfg.GRPC.ClientCAFile != "" || cfg.GRPC.Token != ""
+	if !authenticated && !(cfg.Development && (cfg.GRPC.Plaintext || cfg.GRPC.CertFile != "")) {
+		return nil, errors.New("grpc needs a clientCAFile or a token, serving without is only allowed in development")
+	}
+	if cfg.GRPC.ClientCAFile != "" && cfg.GRPC.CertFile == "" {
+		return nil, errors.New("grpc clientCAFile needs a certFile to serve tls with")
+	}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			append([]grpc.UnaryServerInterceptor{NewErrorInterceptor(log)}, interceptors...)...,
+		),
+	}
+	if cfg.GRPC.CertFile != "" {
+		creds, err := newTLSCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	return grpc.NewServer(opts...), nil
+}
+
+// newTLSCredentials serves TLS with the configured certificate, and requires
+// client certificates signed by ClientCAFile when one is given.
+func newTLSCredentials(cfg *config.Config) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.GRPC.CertFile, cfg.GRPC.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading grpc key pair: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.GRPC.ClientCAFile != "" {
+		ca, err := os.ReadFile(cfg.GRPC.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading grpc client ca at %s: %w", cfg.GRPC.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("parsing grpc client ca at %s", cfg.GRPC.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+func NewErrorInterceptor(log *zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		log.Error().
+			Err(err).
+			Str("method", info.FullMethod).
+			Msg("error occured")
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		uscErr := new(usecase.Error)
+		if errors.As(err, &uscErr) {
+			return nil, status.Error(codeOf(uscErr.Code), uscErr.Message)
+		}
+		return nil, status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+	}
+}
+
+func codeOf(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
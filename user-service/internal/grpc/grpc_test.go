@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewRefusesPlaintext(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := new(config.Config)
+	_, err := New(cfg, &log)
+	assert.NotNil(t, err, "neither tls nor a token")
+	cfg.GRPC.Plaintext = true
+	_, err = New(cfg, &log)
+	assert.NotNil(t, err, "plaintext outside development")
+	cfg.Development = true
+	_, err = New(cfg, &log)
+	assert.Nil(t, err)
+	cfg = new(config.Config)
+	cfg.GRPC.Token = "secret"
+	_, err = New(cfg, &log)
+	assert.Nil(t, err)
+}
+
+func TestNewRefusesUnauthenticatedTLS(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := new(config.Config)
+	cfg.GRPC.CertFile = "server.crt"
+	cfg.GRPC.KeyFile = "server.key"
+	_, err := New(cfg, &log)
+	assert.ErrorContains(t, err, "clientCAFile or a token", "tls without client certificates nor a token")
+	cfg = new(config.Config)
+	cfg.GRPC.ClientCAFile = "ca.crt"
+	_, err = New(cfg, &log)
+	assert.ErrorContains(t, err, "needs a certFile", "client certificates without tls")
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	log := zerolog.Nop()
+	interceptor := NewErrorInterceptor(&log)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+	for name, tc := range map[string]struct {
+		err  error
+		code codes.Code
+	}{
+		"bad request":     {&usecase.Error{Code: http.StatusBadRequest}, codes.InvalidArgument},
+		"unprocessable":   {&usecase.Error{Code: http.StatusUnprocessableEntity}, codes.InvalidArgument},
+		"unauthorized":    {&usecase.Error{Code: http.StatusUnauthorized}, codes.Unauthenticated},
+		"forbidden":       {&usecase.Error{Code: http.StatusForbidden}, codes.PermissionDenied},
+		"not found":       {&usecase.Error{Code: http.StatusNotFound}, codes.NotFound},
+		"conflict":        {&usecase.Error{Code: http.StatusConflict}, codes.AlreadyExists},
+		"other usecase":   {&usecase.Error{Code: http.StatusTeapot}, codes.Internal},
+		"status kept":     {status.Error(codes.Unavailable, "down"), codes.Unavailable},
+		"internal":        {errors.New("connection reset"), codes.Internal},
+		"wrapped usecase": {errors.Join(errors.New("fetch"), &usecase.Error{Code: http.StatusNotFound}), codes.NotFound},
+	} {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			return nil, tc.err
+		})
+		assert.Equal(t, tc.code, status.Code(err), name)
+	}
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, errors.New("password=hunter2")
+	})
+	assert.NotContains(t, err.Error(), "hunter2", "internal errors stay in the log")
+}
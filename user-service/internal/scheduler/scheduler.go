@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// lockID is the postgresql advisory lock key replicas contend for, only the
+// one holding it applies the rules on a given run.
+const lockID int64 = 0x75736572
+
+const (
+	actionPromote  = "promote"
+	actionGraduate = "graduate"
+)
+
+type Scheduler struct {
+	conn    *pgxpool.Pool
+	usecase usecase.IUserUsecase
+	cfg     *config.Config
+	log     *zerolog.Logger
+	running sync.WaitGroup
+}
+
+func New(
+	conn *pgxpool.Pool,
+	usecase usecase.IUserUsecase,
+	cfg *config.Config,
+	log *zerolog.Logger,
+) (*Scheduler, error) {
+	for _, rule := range cfg.Scheduler.Rules {
+		switch types.Status(rule.Status) {
+		case types.StatusApplicant, types.StatusIntern, types.StatusMember, types.StatusAlumni:
+		default:
+			return nil, fmt.Errorf("unknown scheduler rule status %s", rule.Status)
+		}
+		if rule.Action != actionPromote && rule.Action != actionGraduate {
+			return nil, fmt.Errorf("unknown scheduler action %s", rule.Action)
+		}
+		if rule.AfterMonths <= 0 {
+			return nil, fmt.Errorf("scheduler rule for %s must be due after at least a month", rule.Status)
+		}
+	}
+	return &Scheduler{conn: conn, usecase: usecase, cfg: cfg, log: log}, nil
+}
+
+// Start runs the scheduler in the background until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.Run(ctx)
+	}()
+}
+
+// Wait returns once a started scheduler stopped, after the run in flight
+// finished the user it was transitioning.
+func (s *Scheduler) Wait() {
+	s.running.Wait()
+}
+
+// Run applies the rules every configured interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.cfg.Scheduler.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.cfg.Scheduler.Interval) * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := s.tick(ctx); err != nil {
+			s.log.Error().Err(err).Msg("running scheduler")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context) error {
+	conn, err := s.conn.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	defer conn.Release()
+	var leader bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, lockID).Scan(&leader); err != nil {
+		return fmt.Errorf("acquiring advisory lock: %w", err)
+	}
+	if !leader {
+		s.log.Debug().Msg("scheduler lock held by another replica")
+		return nil
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			s.log.Error().Err(err).Msg("releasing advisory lock")
+		}
+	}()
+	for _, rule := range s.cfg.Scheduler.Rules {
+		if err := s.apply(ctx, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) apply(ctx context.Context, rule config.SchedulerRule) error {
+	now := time.Now().UTC()
+	users, err := s.usecase.FetchDue(
+		ctx,
+		types.Status(rule.Status),
+		now.AddDate(0, -rule.AfterMonths, 0),
+	)
+	if err != nil {
+		return fmt.Errorf("fetching users due for %s: %w", rule.Action, err)
+	}
+	transition := s.usecase.Promote
+	if rule.Action == actionGraduate {
+		transition = s.usecase.Graduate
+	}
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		date := user.InternshipStartDate.AddDate(0, rule.AfterMonths, 0)
+		params := &types.TransitionParams{
+			Reason: fmt.Sprintf(
+				"automatic %s after %d months as %s",
+				rule.Action,
+				rule.AfterMonths,
+				rule.Status,
+			),
+			Date: &date,
+		}
+		// a failed user is logged and retried next run, it must not hold
+		// back the rest. Shutting down waits for the transition under way
+		// rather than cutting it off.
+		if _, err := transition(context.WithoutCancel(ctx), user.ID, params); err != nil {
+			s.log.Error().
+				Err(err).
+				Uint64("id", user.ID).
+				Str("action", rule.Action).
+				Msg("applying scheduler rule")
+			continue
+		}
+		s.log.Info().
+			Uint64("id", user.ID).
+			Str("action", rule.Action).
+			Str("reason", params.Reason).
+			Msg("applied scheduler rule")
+	}
+	return nil
+}
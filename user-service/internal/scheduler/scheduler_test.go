@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/internal/config"
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/Lab-ICN/backend/user-service/usecase"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	log := zerolog.Nop()
+	for name, tc := range map[string]struct {
+		rule config.SchedulerRule
+		ok   bool
+	}{
+		"valid":          {config.SchedulerRule{Status: "intern", Action: actionPromote, AfterMonths: 6}, true},
+		"unknown action": {config.SchedulerRule{Status: "intern", Action: "fire", AfterMonths: 6}, false},
+		"typo in status": {config.SchedulerRule{Status: "interns", Action: actionPromote, AfterMonths: 6}, false},
+		"empty status":   {config.SchedulerRule{Action: actionGraduate, AfterMonths: 6}, false},
+		"not yet due":    {config.SchedulerRule{Status: "member", Action: actionGraduate}, false},
+	} {
+		cfg := new(config.Config)
+		cfg.Scheduler.Rules = []config.SchedulerRule{tc.rule}
+		_, err := New(nil, nil, cfg, &log)
+		assert.Equal(t, tc.ok, err == nil, name)
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	store := repository.NewUserFake()
+	users := usecase.NewUserUsecase(store, nil, &log)
+	now := time.Now().UTC()
+	due, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "due@example.com",
+		Username:            "due",
+		InternshipStartDate: now.AddDate(0, -7, 0),
+		Status:              types.StatusIntern,
+	})
+	assert.Nil(t, err)
+	early, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "early@example.com",
+		Username:            "early",
+		InternshipStartDate: now.AddDate(0, -5, 0),
+		Status:              types.StatusIntern,
+	})
+	assert.Nil(t, err)
+	rule := config.SchedulerRule{Status: "intern", Action: actionPromote, AfterMonths: 6}
+	cfg := new(config.Config)
+	cfg.Scheduler.Rules = []config.SchedulerRule{rule}
+	s, err := New(nil, users, cfg, &log)
+	assert.Nil(t, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.NotNil(t, s.apply(canceled, rule), "a stopped scheduler transitions nobody")
+	user, err := users.Fetch(ctx, due)
+	assert.Nil(t, err)
+	assert.Equal(t, types.StatusIntern, user.Status)
+
+	assert.Nil(t, s.apply(ctx, rule))
+	user, err = users.Fetch(ctx, due)
+	assert.Nil(t, err)
+	assert.Equal(t, types.StatusMember, user.Status)
+	assert.Equal(t, now.AddDate(0, -1, 0).Truncate(time.Second), user.InternshipEndDate.Truncate(time.Second),
+		"the internship ends when it was due, not when the scheduler ran")
+	user, err = users.Fetch(ctx, early)
+	assert.Nil(t, err)
+	assert.Equal(t, types.StatusIntern, user.Status)
+	history, err := users.FetchHistory(ctx, due)
+	assert.Nil(t, err)
+	assert.Equal(t, "automatic promote after 6 months as intern", history[0].Reason)
+}
+
+func TestWait(t *testing.T) {
+	log := zerolog.Nop()
+	s, err := New(nil, nil, new(config.Config), &log)
+	assert.Nil(t, err)
+	s.Wait()
+	s.Start(context.Background())
+	s.Wait()
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
 	"github.com/go-faker/faker/v4"
 	"github.com/jackc/pgx/v5"
 )
@@ -31,18 +32,24 @@ func (s *seed) Users(ctx context.Context) {
 	users := make([]repository.User, size)
 	for i := range size {
 		timestamp, _ := time.Parse(time.DateTime, faker.Timestamp())
+		isMember := rand.Intn(2) == 1
+		status := types.StatusIntern
+		if isMember {
+			status = types.StatusMember
+		}
 		users[i] = repository.User{
 			Email:               faker.Email(),
 			Username:            faker.Username(),
 			Fullname:            faker.Name(),
-			IsMember:            rand.Intn(2) == 1,
+			IsMember:            isMember,
+			Status:              string(status),
 			InternshipStartDate: timestamp,
 		}
 	}
 	s.conn.CopyFrom(
 		ctx,
 		pgx.Identifier{"users"},
-		[]string{"email", "username", "fullname", "is_member", "internship_start_date"},
+		[]string{"email", "username", "fullname", "is_member", "internship_start_date", "status"},
 		pgx.CopyFromSlice(len(users), func(i int) ([]interface{}, error) {
 			return []interface{}{
 				users[i].Email,
@@ -50,6 +57,7 @@ func (s *seed) Users(ctx context.Context) {
 				users[i].Fullname,
 				users[i].IsMember,
 				users[i].InternshipStartDate,
+				users[i].Status,
 			}, nil
 		}),
 	)
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Refresh tokens move to token-service's refresh_tokens in the same
+-- database, so its migrations must run first. Copying them over keeps
+-- everyone logged in through the cutover.
+DO $$
+BEGIN
+  IF to_regclass('refresh_tokens') IS NULL THEN
+    RAISE EXCEPTION 'refresh_tokens missing, migrate token-service first';
+  END IF;
+END $$;
+
+INSERT INTO refresh_tokens ("user_id", "token")
+SELECT id, refresh_token
+FROM users
+WHERE refresh_token IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN "refresh_token";
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN "refresh_token" TEXT;
+
+UPDATE users
+SET refresh_token = latest.token
+FROM (
+  SELECT DISTINCT ON (user_id) user_id, token
+  FROM refresh_tokens
+  ORDER BY user_id, created_at DESC
+) latest
+WHERE users.id = latest.user_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users ADD COLUMN "search_vector" TSVECTOR
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', "fullname"), 'A') ||
+    setweight(to_tsvector('simple', "username"), 'B') ||
+    setweight(to_tsvector('simple', "email"), 'C')
+  ) STORED;
+
+CREATE INDEX users_search_vector_idx ON users USING GIN ("search_vector");
+CREATE INDEX users_fullname_trgm_idx ON users USING GIN ("fullname" gin_trgm_ops);
+CREATE INDEX users_username_trgm_idx ON users USING GIN ("username" gin_trgm_ops);
+CREATE INDEX users_email_trgm_idx ON users USING GIN ("email" gin_trgm_ops);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_email_trgm_idx;
+DROP INDEX users_username_trgm_idx;
+DROP INDEX users_fullname_trgm_idx;
+DROP INDEX users_search_vector_idx;
+
+ALTER TABLE users DROP COLUMN "search_vector";
+
+-- +goose StatementEnd
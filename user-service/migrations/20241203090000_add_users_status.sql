@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+  ADD COLUMN "status" TEXT NOT NULL DEFAULT 'intern'
+    CHECK ("status" IN ('applicant', 'intern', 'member', 'alumni')),
+  ADD COLUMN "internship_end_date" DATE;
+
+UPDATE users SET "status" = 'member' WHERE "is_member" = TRUE;
+
+CREATE TABLE user_status_transitions (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" BIGINT NOT NULL REFERENCES users ("id") ON DELETE CASCADE,
+  "from_status" TEXT NOT NULL,
+  "to_status" TEXT NOT NULL,
+  "reason" TEXT NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX user_status_transitions_user_id_idx ON user_status_transitions ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_status_transitions;
+
+ALTER TABLE users
+  DROP COLUMN "internship_end_date",
+  DROP COLUMN "status";
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE applications (
+  "id" BIGSERIAL PRIMARY KEY,
+  "email" TEXT NOT NULL,
+  "username" TEXT NOT NULL,
+  "fullname" TEXT NOT NULL,
+  "motivation" TEXT NOT NULL,
+  "internship_start_date" DATE NOT NULL,
+  "status" TEXT NOT NULL DEFAULT 'pending'
+    CHECK ("status" IN ('pending', 'approved', 'rejected')),
+  "comment" TEXT NOT NULL DEFAULT '',
+  "user_id" BIGINT REFERENCES users ("id") ON DELETE SET NULL,
+  "reviewed_at" TIMESTAMP,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX applications_pending_email_idx ON applications ("email")
+  WHERE "status" = 'pending';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE applications;
+
+-- +goose StatementEnd
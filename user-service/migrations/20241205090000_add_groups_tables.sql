@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE groups (
+  "id" BIGSERIAL PRIMARY KEY,
+  "name" TEXT UNIQUE NOT NULL,
+  "description" TEXT NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE group_members (
+  "group_id" BIGINT NOT NULL REFERENCES groups ("id") ON DELETE CASCADE,
+  "user_id" BIGINT NOT NULL REFERENCES users ("id") ON DELETE CASCADE,
+  "role" TEXT NOT NULL CHECK ("role" IN ('lead', 'member')),
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("group_id", "user_id")
+);
+
+CREATE INDEX group_members_user_id_idx ON group_members ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE group_members;
+DROP TABLE groups;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE profiles (
+  "user_id" BIGINT PRIMARY KEY REFERENCES users ("id") ON DELETE CASCADE,
+  "bio" TEXT NOT NULL DEFAULT '',
+  "interests" TEXT[] NOT NULL DEFAULT '{}',
+  "links" JSONB NOT NULL DEFAULT '{}',
+  "visibility" JSONB NOT NULL DEFAULT '{}',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE profiles;
+
+-- +goose StatementEnd
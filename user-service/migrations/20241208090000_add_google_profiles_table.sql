@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE google_profiles (
+  "user_id" BIGINT PRIMARY KEY REFERENCES users ("id") ON DELETE CASCADE,
+  "name" VARCHAR(255) NOT NULL DEFAULT '',
+  "given_name" VARCHAR(255) NOT NULL DEFAULT '',
+  "family_name" VARCHAR(255) NOT NULL DEFAULT '',
+  "picture" TEXT NOT NULL DEFAULT '',
+  "locale" VARCHAR(35) NOT NULL DEFAULT '',
+  "synced_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE profiles
+ADD COLUMN "name_source" VARCHAR(10) NOT NULL DEFAULT 'admin'
+CHECK ("name_source" IN ('admin', 'google'));
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE profiles DROP COLUMN "name_source";
+
+DROP TABLE google_profiles;
+
+-- +goose StatementEnd
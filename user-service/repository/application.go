@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type applicationPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewApplicationPostgreSQL(conn *pgxpool.Pool) IApplicationStorage {
+	return &applicationPostgreSQL{conn}
+}
+
+func (p *applicationPostgreSQL) Create(
+	ctx context.Context,
+	application *types.CreateApplicationParams,
+) (uint64, error) {
+	var id uint64
+	if err := p.conn.QueryRow(ctx, `
+		INSERT INTO applications ("email", "username", "fullname", "motivation", "internship_start_date")
+		VALUES (@email, @username, @fullname, @motivation, @internship_start_date)
+		RETURNING id`,
+		pgx.NamedArgs{
+			"email":                 application.Email,
+			"username":              application.Username,
+			"fullname":              application.Fullname,
+			"motivation":            application.Motivation,
+			"internship_start_date": application.InternshipStartDate,
+		},
+	).Scan(&id); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, ErrDuplicateRow
+		}
+		return 0, fmt.Errorf("inserting application for email %s: %w", application.Email, err)
+	}
+	return id, nil
+}
+
+func (p *applicationPostgreSQL) List(
+	ctx context.Context,
+	status types.ApplicationStatus,
+) ([]Application, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			email,
+			username,
+			fullname,
+			motivation,
+			internship_start_date,
+			status,
+			comment,
+			user_id,
+			reviewed_at,
+			created_at
+		FROM applications
+		WHERE status = $1
+		ORDER BY created_at
+		LIMIT $2`, status, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting %s applications: %w", status, err)
+	}
+	applications, err := pgx.CollectRows(rows, pgx.RowToStructByName[Application])
+	if err != nil {
+		return nil, fmt.Errorf("parsing applications: %w", err)
+	}
+	return applications, nil
+}
+
+func (p *applicationPostgreSQL) Get(ctx context.Context, id uint64) (Application, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			email,
+			username,
+			fullname,
+			motivation,
+			internship_start_date,
+			status,
+			comment,
+			user_id,
+			reviewed_at,
+			created_at
+		FROM applications WHERE id = $1`, id)
+	if err != nil {
+		return Application{}, fmt.Errorf("selecting application for id %d: %w", id, err)
+	}
+	application, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Application])
+	if err != nil {
+		if errors.Is(pgx.ErrNoRows, err) {
+			return Application{}, ErrNoRow
+		}
+		return Application{}, fmt.Errorf("parsing application: %w", err)
+	}
+	return application, nil
+}
+
+// Review settles a pending application, failing with ErrNoRowAffected when
+// it has already been reviewed.
+func (p *applicationPostgreSQL) Review(
+	ctx context.Context,
+	params *types.UpdateApplicationParams,
+) error {
+	tag, err := p.conn.Exec(ctx, `
+		UPDATE applications
+		SET
+			status = @status,
+			comment = @comment,
+			user_id = @user_id,
+			reviewed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = @id AND status = 'pending'`,
+		pgx.NamedArgs{
+			"id":      params.ID,
+			"status":  params.Status,
+			"comment": params.Comment,
+			"user_id": params.UserID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("reviewing application for id %d: %w", params.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
+
+// Approve fails with ErrDuplicateRow when the user already exists and with
+// ErrNoRowAffected when the application has already been reviewed, leaving
+// neither registered.
+func (p *applicationPostgreSQL) Approve(
+	ctx context.Context,
+	params *types.UpdateApplicationParams,
+	user *types.CreateUserParams,
+) (uint64, error) {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	id, err := createUser(ctx, tx, user)
+	if err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE applications
+		SET
+			status = @status,
+			comment = @comment,
+			user_id = @user_id,
+			reviewed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = @id AND status = 'pending'`,
+		pgx.NamedArgs{
+			"id":      params.ID,
+			"status":  types.ApplicationApproved,
+			"comment": params.Comment,
+			"user_id": id,
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("reviewing application for id %d: %w", params.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, ErrNoRowAffected
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return id, nil
+}
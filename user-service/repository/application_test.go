@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationReview(t *testing.T) {
+	ctx := context.Background()
+	applications := repository.NewApplicationPostgreSQL(conn)
+	id, err := applications.Create(ctx, &types.CreateApplicationParams{
+		Email:               "applicant@example.com",
+		Username:            "applicant",
+		Fullname:            "Applicant User",
+		Motivation:          "I want to join the lab",
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	_, err = applications.Create(ctx, &types.CreateApplicationParams{
+		Email:               "applicant@example.com",
+		Username:            "applicant",
+		Fullname:            "Applicant User",
+		Motivation:          "Applying twice",
+		InternshipStartDate: time.Now(),
+	})
+	assert.ErrorIs(t, err, repository.ErrDuplicateRow)
+	err = applications.Review(ctx, &types.UpdateApplicationParams{
+		ID:      id,
+		Status:  types.ApplicationRejected,
+		Comment: "no open positions",
+	})
+	assert.Nil(t, err)
+	err = applications.Review(ctx, &types.UpdateApplicationParams{
+		ID:     id,
+		Status: types.ApplicationApproved,
+	})
+	assert.ErrorIs(t, err, repository.ErrNoRowAffected)
+	pending, err := applications.List(ctx, types.ApplicationPending)
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestApplicationApprove(t *testing.T) {
+	ctx := context.Background()
+	applications := repository.NewApplicationPostgreSQL(conn)
+	id, err := applications.Create(ctx, &types.CreateApplicationParams{
+		Email:               "approved@example.com",
+		Username:            "approved",
+		Fullname:            "Approved User",
+		Motivation:          "I want to join the lab",
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	user := &types.CreateUserParams{
+		Email:               "approved@example.com",
+		Username:            "approved",
+		Fullname:            "Approved User",
+		Status:              types.StatusIntern,
+		InternshipStartDate: time.Now(),
+	}
+	_, err = store.Create(ctx, user)
+	assert.Nil(t, err)
+	_, err = applications.Approve(ctx, &types.UpdateApplicationParams{ID: id}, user)
+	assert.ErrorIs(t, err, repository.ErrDuplicateRow)
+	application, err := applications.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, string(types.ApplicationPending), application.Status, "nothing is reviewed without the user")
+
+	user.Email, user.Username = "approved2@example.com", "approved2"
+	userID, err := applications.Approve(ctx, &types.UpdateApplicationParams{ID: id, Comment: "welcome"}, user)
+	assert.Nil(t, err)
+	application, err = applications.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, string(types.ApplicationApproved), application.Status)
+	assert.Equal(t, &userID, application.UserID)
+
+	user.Email, user.Username = "approved3@example.com", "approved3"
+	_, err = applications.Approve(ctx, &types.UpdateApplicationParams{ID: id}, user)
+	assert.ErrorIs(t, err, repository.ErrNoRowAffected)
+	_, err = store.GetByEmail(ctx, "approved3@example.com")
+	assert.ErrorIs(t, err, repository.ErrNoRow, "nobody is registered for a reviewed application")
+}
@@ -12,7 +12,10 @@ type User struct {
 	Username            string
 	Fullname            string
 	IsMember            bool
+	Status              string
 	InternshipStartDate time.Time
+	InternshipEndDate   *time.Time
+	AvatarPath          *string
 }
 
 func (u User) DTO() types.User {
@@ -22,6 +25,159 @@ func (u User) DTO() types.User {
 		Username:            u.Username,
 		Fullname:            u.Fullname,
 		IsMember:            u.IsMember,
+		Status:              types.Status(u.Status),
 		InternshipStartDate: u.InternshipStartDate,
+		InternshipEndDate:   u.InternshipEndDate,
 	}
 }
+
+type StatusTransition struct {
+	ID         uint64
+	UserID     uint64
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+func (t StatusTransition) DTO() types.StatusTransition {
+	return types.StatusTransition{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		FromStatus: types.Status(t.FromStatus),
+		ToStatus:   types.Status(t.ToStatus),
+		Reason:     t.Reason,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+type UserSearchResult struct {
+	User
+	Rank float64
+	// Matched is the text searched, which the usecase highlights
+	Matched string
+}
+
+func (u UserSearchResult) DTO() types.UserSearchResult {
+	return types.UserSearchResult{
+		User: u.User.DTO(),
+		Rank: u.Rank,
+	}
+}
+
+type Application struct {
+	ID                  uint64
+	Email               string
+	Username            string
+	Fullname            string
+	Motivation          string
+	InternshipStartDate time.Time
+	Status              string
+	Comment             string
+	UserID              *uint64
+	ReviewedAt          *time.Time
+	CreatedAt           time.Time
+}
+
+func (a Application) DTO() types.Application {
+	return types.Application{
+		ID:                  a.ID,
+		Email:               a.Email,
+		Username:            a.Username,
+		Fullname:            a.Fullname,
+		Motivation:          a.Motivation,
+		InternshipStartDate: a.InternshipStartDate,
+		Status:              types.ApplicationStatus(a.Status),
+		Comment:             a.Comment,
+		UserID:              a.UserID,
+		ReviewedAt:          a.ReviewedAt,
+		CreatedAt:           a.CreatedAt,
+	}
+}
+
+type Group struct {
+	ID          uint64
+	Name        string
+	Description string
+}
+
+func (g Group) DTO() types.Group {
+	return types.Group{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+	}
+}
+
+type GroupMember struct {
+	UserID   uint64
+	Username string
+	Fullname string
+	Role     string
+}
+
+func (m GroupMember) DTO() types.GroupMember {
+	return types.GroupMember{
+		UserID:   m.UserID,
+		Username: m.Username,
+		Fullname: m.Fullname,
+		Role:     types.GroupRole(m.Role),
+	}
+}
+
+type UserGroup struct {
+	ID   uint64
+	Name string
+	Role string
+}
+
+func (g UserGroup) DTO() types.UserGroup {
+	return types.UserGroup{
+		ID:   g.ID,
+		Name: g.Name,
+		Role: types.GroupRole(g.Role),
+	}
+}
+
+type Profile struct {
+	Bio        string
+	Interests  []string
+	Links      map[string]string
+	Visibility map[string]bool
+	NameSource string
+}
+
+func (p Profile) DTO() types.Profile {
+	return types.Profile{
+		Bio:        p.Bio,
+		Interests:  p.Interests,
+		Links:      p.Links,
+		Visibility: p.Visibility,
+		NameSource: types.NameSource(p.NameSource),
+	}
+}
+
+type GoogleProfile struct {
+	Name       string
+	GivenName  string
+	FamilyName string
+	Picture    string
+	Locale     string
+	SyncedAt   time.Time
+}
+
+func (g GoogleProfile) DTO() types.GoogleProfile {
+	return types.GoogleProfile{
+		Name:       g.Name,
+		GivenName:  g.GivenName,
+		FamilyName: g.FamilyName,
+		Picture:    g.Picture,
+		Locale:     g.Locale,
+		SyncedAt:   g.SyncedAt,
+	}
+}
+
+type ProfiledUser struct {
+	User
+	Profile
+}
@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/types"
+)
+
+type fakeUser struct {
+	mu          sync.Mutex
+	nextID      uint64
+	users       map[uint64]User
+	profiles    map[uint64]Profile
+	google      map[uint64]GoogleProfile
+	transitions []StatusTransition
+}
+
+// NewUserFake keeps users in memory, for tests.
+func NewUserFake() IUserStorage {
+	return &fakeUser{
+		users:    map[uint64]User{},
+		profiles: map[uint64]Profile{},
+		google:   map[uint64]GoogleProfile{},
+	}
+}
+
+func (f *fakeUser) Create(ctx context.Context, user *types.CreateUserParams) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.create(user)
+}
+
+func (f *fakeUser) create(user *types.CreateUserParams) (uint64, error) {
+	for _, u := range f.users {
+		if u.Email == user.Email || u.Username == user.Username {
+			return 0, ErrDuplicateRow
+		}
+	}
+	f.nextID++
+	f.users[f.nextID] = User{
+		ID:                  f.nextID,
+		Email:               user.Email,
+		Username:            user.Username,
+		Fullname:            user.Fullname,
+		IsMember:            user.IsMember,
+		Status:              string(statusOf(user)),
+		InternshipStartDate: user.InternshipStartDate,
+	}
+	return f.nextID, nil
+}
+
+func (f *fakeUser) CreateBulk(ctx context.Context, users []types.CreateUserParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range users {
+		if _, err := f.create(&users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sorted lists the users matching keep by id, the order they were created.
+func (f *fakeUser) sorted(keep func(User) bool) []User {
+	users := []User{}
+	for _, u := range f.users {
+		if keep(u) {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (f *fakeUser) List(ctx context.Context) ([]User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(func(User) bool { return true }), nil
+}
+
+func (f *fakeUser) ListPassed(ctx context.Context, year uint) ([]User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(func(u User) bool {
+		return u.IsMember && uint(u.InternshipStartDate.Year()) == year
+	}), nil
+}
+
+func (f *fakeUser) Get(ctx context.Context, id uint64) (User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return User{}, ErrNoRow
+	}
+	return user, nil
+}
+
+func (f *fakeUser) GetByEmail(ctx context.Context, email string) (User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, ErrNoRow
+}
+
+func (f *fakeUser) ListDue(ctx context.Context, status types.Status, startedBefore time.Time) ([]User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(func(u User) bool {
+		return u.Status == string(status) && !u.InternshipStartDate.After(startedBefore)
+	}), nil
+}
+
+func (f *fakeUser) ListBatch(ctx context.Context, ids []uint64, emails []string) ([]User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(func(u User) bool {
+		return slices.Contains(ids, u.ID) || slices.Contains(emails, u.Email)
+	}), nil
+}
+
+// Search only matches substrings, ranking every match alike.
+func (f *fakeUser) Search(ctx context.Context, query string, limit uint) ([]UserSearchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.ToLower(query)
+	results := []UserSearchResult{}
+	for _, u := range f.sorted(func(u User) bool {
+		return strings.Contains(strings.ToLower(u.Fullname+" "+u.Username+" "+u.Email), query)
+	}) {
+		if uint(len(results)) == limit {
+			break
+		}
+		results = append(results, UserSearchResult{
+			User:    u,
+			Rank:    1,
+			Matched: u.Fullname + " " + u.Username + " " + u.Email,
+		})
+	}
+	return results, nil
+}
+
+func (f *fakeUser) Transition(ctx context.Context, params *types.UpdateStatusParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[params.UserID]
+	if !ok || user.Status != string(params.From) {
+		return ErrNoRowAffected
+	}
+	user.Status = string(params.To)
+	user.IsMember = params.To == types.StatusMember
+	if params.InternshipStartDate != nil {
+		user.InternshipStartDate = *params.InternshipStartDate
+	}
+	if params.InternshipEndDate != nil {
+		user.InternshipEndDate = params.InternshipEndDate
+	}
+	f.users[user.ID] = user
+	f.transitions = append(f.transitions, StatusTransition{
+		ID:         uint64(len(f.transitions) + 1),
+		UserID:     user.ID,
+		FromStatus: string(params.From),
+		ToStatus:   string(params.To),
+		Reason:     params.Reason,
+		CreatedAt:  time.Now(),
+	})
+	return nil
+}
+
+func (f *fakeUser) ListTransitions(ctx context.Context, id uint64) ([]StatusTransition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	transitions := []StatusTransition{}
+	for _, t := range f.transitions {
+		if t.UserID == id {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions, nil
+}
+
+func (f *fakeUser) SetAvatar(ctx context.Context, id uint64, path *string) (*string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return nil, ErrNoRow
+	}
+	previous := user.AvatarPath
+	user.AvatarPath = path
+	f.users[id] = user
+	return previous, nil
+}
+
+func (f *fakeUser) GetProfile(ctx context.Context, id uint64) (Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.users[id]; !ok {
+		return Profile{}, ErrNoRow
+	}
+	return f.profile(id), nil
+}
+
+// profile is the profile of id, empty for users who never saved one as
+// GetProfile of postgresql returns.
+func (f *fakeUser) profile(id uint64) Profile {
+	profile, ok := f.profiles[id]
+	if !ok {
+		return Profile{
+			Interests:  []string{},
+			Links:      map[string]string{},
+			Visibility: map[string]bool{},
+			NameSource: string(types.NameSourceAdmin),
+		}
+	}
+	return profile
+}
+
+func (f *fakeUser) PutProfile(ctx context.Context, id uint64, profile *types.ProfileParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return ErrNoRow
+	}
+	f.profiles[id] = Profile{
+		Bio:        profile.Bio,
+		Interests:  profile.Interests,
+		Links:      profile.Links,
+		Visibility: profile.Visibility,
+		NameSource: string(profile.NameSource),
+	}
+	if google, ok := f.google[id]; ok && profile.NameSource == types.NameSourceGoogle && google.Name != "" {
+		user.Fullname = google.Name
+		f.users[id] = user
+	}
+	return nil
+}
+
+func (f *fakeUser) ListDirectory(ctx context.Context) ([]ProfiledUser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	directory := []ProfiledUser{}
+	for _, u := range f.sorted(func(u User) bool { return u.Status == string(types.StatusMember) }) {
+		directory = append(directory, ProfiledUser{User: u, Profile: f.profile(u.ID)})
+	}
+	sort.SliceStable(directory, func(i, j int) bool {
+		return directory[i].Fullname < directory[j].Fullname
+	})
+	return directory, nil
+}
+
+func (f *fakeUser) GetGoogleProfile(ctx context.Context, id uint64) (GoogleProfile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	profile, ok := f.google[id]
+	if !ok {
+		return GoogleProfile{}, ErrNoRow
+	}
+	return profile, nil
+}
+
+func (f *fakeUser) SyncGoogleProfile(ctx context.Context, id uint64, profile *types.GoogleProfileParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return ErrNoRow
+	}
+	f.google[id] = GoogleProfile{
+		Name:       profile.Name,
+		GivenName:  profile.GivenName,
+		FamilyName: profile.FamilyName,
+		Picture:    profile.Picture,
+		Locale:     profile.Locale,
+		SyncedAt:   time.Now(),
+	}
+	if profile.Name != "" && f.profile(id).NameSource == string(types.NameSourceGoogle) {
+		user.Fullname = profile.Name
+		f.users[id] = user
+	}
+	return nil
+}
+
+func (f *fakeUser) Delete(ctx context.Context, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.users, id)
+	delete(f.profiles, id)
+	delete(f.google, id)
+	return nil
+}
+
+type fakeApplication struct {
+	mu           sync.Mutex
+	users        IUserStorage
+	applications map[uint64]Application
+}
+
+// NewApplicationFake keeps applications in memory, registering approved
+// candidates in users, for tests.
+func NewApplicationFake(users IUserStorage) IApplicationStorage {
+	return &fakeApplication{users: users, applications: map[uint64]Application{}}
+}
+
+func (f *fakeApplication) Create(ctx context.Context, application *types.CreateApplicationParams) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, a := range f.applications {
+		if a.Email == application.Email && a.Status == string(types.ApplicationPending) {
+			return 0, ErrDuplicateRow
+		}
+	}
+	id := uint64(len(f.applications) + 1)
+	f.applications[id] = Application{
+		ID:                  id,
+		Email:               application.Email,
+		Username:            application.Username,
+		Fullname:            application.Fullname,
+		Motivation:          application.Motivation,
+		InternshipStartDate: application.InternshipStartDate,
+		Status:              string(types.ApplicationPending),
+		CreatedAt:           time.Now(),
+	}
+	return id, nil
+}
+
+func (f *fakeApplication) List(ctx context.Context, status types.ApplicationStatus) ([]Application, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	applications := []Application{}
+	for _, a := range f.applications {
+		if a.Status == string(status) {
+			applications = append(applications, a)
+		}
+	}
+	sort.Slice(applications, func(i, j int) bool { return applications[i].ID < applications[j].ID })
+	return applications, nil
+}
+
+func (f *fakeApplication) Get(ctx context.Context, id uint64) (Application, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	application, ok := f.applications[id]
+	if !ok {
+		return Application{}, ErrNoRow
+	}
+	return application, nil
+}
+
+func (f *fakeApplication) Review(ctx context.Context, params *types.UpdateApplicationParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.review(params)
+}
+
+func (f *fakeApplication) review(params *types.UpdateApplicationParams) error {
+	application, ok := f.applications[params.ID]
+	if !ok || application.Status != string(types.ApplicationPending) {
+		return ErrNoRowAffected
+	}
+	now := time.Now()
+	application.Status = string(params.Status)
+	application.Comment = params.Comment
+	application.UserID = params.UserID
+	application.ReviewedAt = &now
+	f.applications[params.ID] = application
+	return nil
+}
+
+func (f *fakeApplication) Approve(
+	ctx context.Context,
+	params *types.UpdateApplicationParams,
+	user *types.CreateUserParams,
+) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if application, ok := f.applications[params.ID]; !ok || application.Status != string(types.ApplicationPending) {
+		return 0, ErrNoRowAffected
+	}
+	id, err := f.users.Create(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	return id, f.review(&types.UpdateApplicationParams{
+		ID:      params.ID,
+		Status:  types.ApplicationApproved,
+		Comment: params.Comment,
+		UserID:  &id,
+	})
+}
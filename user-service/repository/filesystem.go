@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type filesystem struct {
+	dir     string
+	baseURL string
+}
+
+// NewObjectFilesystem keeps objects as files under dir, served publicly
+// under baseURL.
+func NewObjectFilesystem(dir, baseURL string) IObjectStorage {
+	return &filesystem{dir, strings.TrimRight(baseURL, "/")}
+}
+
+func (f *filesystem) Put(
+	ctx context.Context,
+	key string,
+	r io.Reader,
+	size int64,
+	contentType string,
+) error {
+	path := filepath.Join(f.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", key, err)
+	}
+	// written aside then renamed so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("changing mode of %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming %s: %w", key, err)
+	}
+	return nil
+}
+
+func (f *filesystem) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(f.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("deleting %s: %w", key, err)
+	}
+	return nil
+}
+
+func (f *filesystem) URL(key string) string {
+	return f.baseURL + "/" + key
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type groupPostgreSQL struct {
+	conn *pgxpool.Pool
+}
+
+func NewGroupPostgreSQL(conn *pgxpool.Pool) IGroupStorage {
+	return &groupPostgreSQL{conn}
+}
+
+func (p *groupPostgreSQL) Create(ctx context.Context, group *types.GroupParams) (uint64, error) {
+	var id uint64
+	if err := p.conn.QueryRow(ctx, `
+		INSERT INTO groups ("name", "description")
+		VALUES ($1, $2)
+		RETURNING id`, group.Name, group.Description,
+	).Scan(&id); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, ErrDuplicateRow
+		}
+		return 0, fmt.Errorf("inserting group %s: %w", group.Name, err)
+	}
+	return id, nil
+}
+
+func (p *groupPostgreSQL) List(ctx context.Context) ([]Group, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			name,
+			description
+		FROM groups
+		ORDER BY name
+		LIMIT $1`, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting groups: %w", err)
+	}
+	groups, err := pgx.CollectRows(rows, pgx.RowToStructByName[Group])
+	if err != nil {
+		return nil, fmt.Errorf("parsing groups: %w", err)
+	}
+	return groups, nil
+}
+
+func (p *groupPostgreSQL) Get(ctx context.Context, id uint64) (Group, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			name,
+			description
+		FROM groups WHERE id = $1`, id)
+	if err != nil {
+		return Group{}, fmt.Errorf("selecting group for id %d: %w", id, err)
+	}
+	group, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Group])
+	if err != nil {
+		if errors.Is(pgx.ErrNoRows, err) {
+			return Group{}, ErrNoRow
+		}
+		return Group{}, fmt.Errorf("parsing group: %w", err)
+	}
+	return group, nil
+}
+
+func (p *groupPostgreSQL) Update(ctx context.Context, id uint64, group *types.GroupParams) error {
+	tag, err := p.conn.Exec(ctx, `
+		UPDATE groups
+		SET
+			name = $2,
+			description = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, group.Name, group.Description,
+	)
+	if err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateRow
+		}
+		return fmt.Errorf("updating group for id %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	return nil
+}
+
+func (p *groupPostgreSQL) Delete(ctx context.Context, id uint64) error {
+	_, err := p.conn.Exec(ctx, `DELETE FROM groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting group for id %d: %w", id, err)
+	}
+	return nil
+}
+
+func (p *groupPostgreSQL) ListMembers(ctx context.Context, id uint64) ([]GroupMember, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			u.id AS user_id,
+			u.username,
+			u.fullname,
+			m.role
+		FROM group_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.group_id = $1
+		ORDER BY m.role, u.fullname
+		LIMIT $2`, id, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting members of group %d: %w", id, err)
+	}
+	members, err := pgx.CollectRows(rows, pgx.RowToStructByName[GroupMember])
+	if err != nil {
+		return nil, fmt.Errorf("parsing group members: %w", err)
+	}
+	return members, nil
+}
+
+// PutMember adds the user to the group or changes their role in it, failing
+// with ErrNoRow when either does not exist.
+func (p *groupPostgreSQL) PutMember(
+	ctx context.Context,
+	id, userID uint64,
+	role types.GroupRole,
+) error {
+	if _, err := p.conn.Exec(ctx, `
+		INSERT INTO group_members ("group_id", "user_id", "role")
+		VALUES ($1, $2, $3)
+		ON CONFLICT ("group_id", "user_id") DO UPDATE
+		SET role = EXCLUDED.role`, id, userID, role,
+	); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNoRow
+		}
+		return fmt.Errorf("putting user %d into group %d: %w", userID, id, err)
+	}
+	return nil
+}
+
+func (p *groupPostgreSQL) DeleteMember(ctx context.Context, id, userID uint64) error {
+	_, err := p.conn.Exec(ctx, `
+		DELETE FROM group_members
+		WHERE group_id = $1 AND user_id = $2`, id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting user %d from group %d: %w", userID, id, err)
+	}
+	return nil
+}
+
+func (p *groupPostgreSQL) ListByUser(ctx context.Context, userID uint64) ([]UserGroup, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			g.id,
+			g.name,
+			m.role
+		FROM group_members m
+		JOIN groups g ON g.id = m.group_id
+		WHERE m.user_id = $1
+		ORDER BY g.name`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting groups of user %d: %w", userID, err)
+	}
+	groups, err := pgx.CollectRows(rows, pgx.RowToStructByName[UserGroup])
+	if err != nil {
+		return nil, fmt.Errorf("parsing user groups: %w", err)
+	}
+	return groups, nil
+}
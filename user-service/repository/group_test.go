@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMembership(t *testing.T) {
+	ctx := context.Background()
+	groups := repository.NewGroupPostgreSQL(conn)
+	id, err := groups.Create(ctx, &types.GroupParams{Name: "Networking"})
+	assert.Nil(t, err)
+	_, err = groups.Create(ctx, &types.GroupParams{Name: "Networking"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateRow)
+	userID, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "lead@example.com",
+		Username:            "lead",
+		Fullname:            "Group Lead",
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, groups.PutMember(ctx, id, userID, types.RoleMember))
+	assert.Nil(t, groups.PutMember(ctx, id, userID, types.RoleLead))
+	assert.ErrorIs(t, groups.PutMember(ctx, id, 0, types.RoleMember), repository.ErrNoRow)
+	userGroups, err := groups.ListByUser(ctx, userID)
+	assert.Nil(t, err)
+	assert.Len(t, userGroups, 1)
+	assert.Equal(t, string(types.RoleLead), userGroups[0].Role)
+	assert.Nil(t, groups.Delete(ctx, id))
+}
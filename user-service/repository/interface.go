@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/Lab-ICN/backend/user-service/types"
 )
@@ -13,5 +15,46 @@ type IUserStorage interface {
 	ListPassed(ctx context.Context, year uint) ([]User, error)
 	Get(ctx context.Context, id uint64) (User, error)
 	GetByEmail(ctx context.Context, email string) (User, error)
+	ListDue(ctx context.Context, status types.Status, startedBefore time.Time) ([]User, error)
+	ListBatch(ctx context.Context, ids []uint64, emails []string) ([]User, error)
+	Search(ctx context.Context, query string, limit uint) ([]UserSearchResult, error)
+	Transition(ctx context.Context, params *types.UpdateStatusParams) error
+	SetAvatar(ctx context.Context, id uint64, path *string) (*string, error)
+	GetProfile(ctx context.Context, id uint64) (Profile, error)
+	PutProfile(ctx context.Context, id uint64, profile *types.ProfileParams) error
+	ListDirectory(ctx context.Context) ([]ProfiledUser, error)
+	GetGoogleProfile(ctx context.Context, id uint64) (GoogleProfile, error)
+	SyncGoogleProfile(ctx context.Context, id uint64, profile *types.GoogleProfileParams) error
+	ListTransitions(ctx context.Context, id uint64) ([]StatusTransition, error)
 	Delete(ctx context.Context, id uint64) error
 }
+
+type IApplicationStorage interface {
+	Create(ctx context.Context, application *types.CreateApplicationParams) (uint64, error)
+	List(ctx context.Context, status types.ApplicationStatus) ([]Application, error)
+	Get(ctx context.Context, id uint64) (Application, error)
+	Review(ctx context.Context, params *types.UpdateApplicationParams) error
+	// Approve registers user and settles the application as approved for
+	// them at once, returning the user's ID.
+	Approve(ctx context.Context, params *types.UpdateApplicationParams, user *types.CreateUserParams) (uint64, error)
+}
+
+type IGroupStorage interface {
+	Create(ctx context.Context, group *types.GroupParams) (uint64, error)
+	List(ctx context.Context) ([]Group, error)
+	Get(ctx context.Context, id uint64) (Group, error)
+	Update(ctx context.Context, id uint64, group *types.GroupParams) error
+	Delete(ctx context.Context, id uint64) error
+	ListMembers(ctx context.Context, id uint64) ([]GroupMember, error)
+	PutMember(ctx context.Context, id, userID uint64, role types.GroupRole) error
+	DeleteMember(ctx context.Context, id, userID uint64) error
+	ListByUser(ctx context.Context, userID uint64) ([]UserGroup, error)
+}
+
+// IObjectStorage keeps public files such as avatars under slash separated
+// keys.
+type IObjectStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
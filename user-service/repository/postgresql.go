@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Lab-ICN/backend/user-service/types"
 	"github.com/jackc/pgx/v5"
@@ -21,11 +22,22 @@ func NewUserPostgreSQL(conn *pgxpool.Pool) IUserStorage {
 	return &postgresql{conn}
 }
 
+// querier is the pool, or a transaction of it a statement has to be part of.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 func (p *postgresql) Create(ctx context.Context, user *types.CreateUserParams) (uint64, error) {
+	return createUser(ctx, p.conn, user)
+}
+
+// createUser is the only insert of users, which approving an application
+// runs in the transaction of its review.
+func createUser(ctx context.Context, q querier, user *types.CreateUserParams) (uint64, error) {
 	var id uint64
-	if err := p.conn.QueryRow(ctx, `
-        INSERT INTO users ("email", "username", "fullname", "is_member", "internship_start_date")
-        VALUES (@email, @username, @fullname, @is_member, @internship_start_date)
+	if err := q.QueryRow(ctx, `
+        INSERT INTO users ("email", "username", "fullname", "is_member", "internship_start_date", "status")
+        VALUES (@email, @username, @fullname, @is_member, @internship_start_date, @status)
         RETURNING id`,
 		pgx.NamedArgs{
 			"email":                 user.Email,
@@ -33,6 +45,7 @@ func (p *postgresql) Create(ctx context.Context, user *types.CreateUserParams) (
 			"fullname":              user.Fullname,
 			"is_member":             user.IsMember,
 			"internship_start_date": user.InternshipStartDate,
+			"status":                statusOf(user),
 		},
 	).Scan(&id); err != nil {
 		pgErr := new(pgconn.PgError)
@@ -48,7 +61,7 @@ func (p *postgresql) CreateBulk(ctx context.Context, users []types.CreateUserPar
 	affected, err := p.conn.CopyFrom(
 		ctx,
 		pgx.Identifier{"users"},
-		[]string{"email", "username", "fullname", "is_member", "internship_start_date"},
+		[]string{"email", "username", "fullname", "is_member", "internship_start_date", "status"},
 		pgx.CopyFromSlice(len(users), func(i int) ([]interface{}, error) {
 			return []interface{}{
 				users[i].Email,
@@ -56,6 +69,7 @@ func (p *postgresql) CreateBulk(ctx context.Context, users []types.CreateUserPar
 				users[i].Fullname,
 				users[i].IsMember,
 				users[i].InternshipStartDate,
+				statusOf(&users[i]),
 			}, nil
 		}),
 	)
@@ -80,7 +94,10 @@ func (p *postgresql) List(ctx context.Context) ([]User, error) {
 			username,
 			fullname,
 			is_member,
-			internship_start_date
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
 		FROM users
 		ORDER BY created_at
 		LIMIT $1`, maxRecords,
@@ -103,7 +120,10 @@ func (p *postgresql) ListPassed(ctx context.Context, year uint) ([]User, error)
 			username,
 			fullname,
 			is_member,
-			internship_start_date
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
 		FROM users
 		WHERE is_member = TRUE AND
 		EXTRACT(YEAR FROM internship_start_date) = $1
@@ -120,6 +140,38 @@ func (p *postgresql) ListPassed(ctx context.Context, year uint) ([]User, error)
 	return users, nil
 }
 
+func (p *postgresql) ListDue(
+	ctx context.Context,
+	status types.Status,
+	startedBefore time.Time,
+) ([]User, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			email,
+			username,
+			fullname,
+			is_member,
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
+		FROM users
+		WHERE status = $1 AND
+		internship_start_date <= $2
+		ORDER BY internship_start_date
+		LIMIT $3`, status, startedBefore, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting %s users started before %s: %w", status, startedBefore, err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[User])
+	if err != nil {
+		return nil, fmt.Errorf("parsing users: %w", err)
+	}
+	return users, nil
+}
+
 func (p *postgresql) Get(ctx context.Context, id uint64) (User, error) {
 	rows, err := p.conn.Query(ctx, `
 		SELECT 
@@ -128,7 +180,10 @@ func (p *postgresql) Get(ctx context.Context, id uint64) (User, error) {
 			username,
 			fullname,
 			is_member,
-			internship_start_date
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
 		FROM users WHERE id = $1`, id)
 	if err != nil {
 		return User{}, fmt.Errorf("selecting user for id %d: %w", id, err)
@@ -151,7 +206,10 @@ func (p *postgresql) GetByEmail(ctx context.Context, email string) (User, error)
 			username,
 			fullname,
 			is_member,
-			internship_start_date
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
 		FROM users WHERE email = $1`, email)
 	if err != nil {
 		return User{}, fmt.Errorf("selecting user for email %s: %w", email, err)
@@ -166,6 +224,365 @@ func (p *postgresql) GetByEmail(ctx context.Context, email string) (User, error)
 	return user, nil
 }
 
+func (p *postgresql) ListBatch(
+	ctx context.Context,
+	ids []uint64,
+	emails []string,
+) ([]User, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			email,
+			username,
+			fullname,
+			is_member,
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path
+		FROM users
+		WHERE id = ANY($1) OR email = ANY($2)
+		ORDER BY created_at
+		LIMIT $3`, ids, emails, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"selecting users for %d ids and %d emails: %w",
+			len(ids),
+			len(emails),
+			err,
+		)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[User])
+	if err != nil {
+		return nil, fmt.Errorf("parsing users: %w", err)
+	}
+	return users, nil
+}
+
+// Search matches whole words through the full-text vector and partial or
+// misspelled ones through trigram word similarity, ranking by both. Matched
+// holds the fullname, username and email as searched, unmarked.
+func (p *postgresql) Search(
+	ctx context.Context,
+	query string,
+	limit uint,
+) ([]UserSearchResult, error) {
+	rows, err := p.conn.Query(ctx, `
+		WITH q AS (
+			SELECT websearch_to_tsquery('simple', $1) AS tsquery
+		)
+		SELECT
+			id,
+			email,
+			username,
+			fullname,
+			is_member,
+			internship_start_date,
+			status,
+			internship_end_date,
+			avatar_path,
+			ts_rank(search_vector, q.tsquery) + GREATEST(
+				word_similarity($1, fullname),
+				word_similarity($1, username),
+				word_similarity($1, email)
+			) AS rank,
+			fullname || ' ' || username || ' ' || email AS matched
+		FROM users, q
+		WHERE search_vector @@ q.tsquery OR
+		$1 <% fullname OR
+		$1 <% username OR
+		$1 <% email
+		ORDER BY rank DESC
+		LIMIT $2`, query, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching users for %s: %w", query, err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[UserSearchResult])
+	if err != nil {
+		return nil, fmt.Errorf("parsing users: %w", err)
+	}
+	return users, nil
+}
+
+// Transition moves a user from one status to another and records it, failing
+// with ErrNoRowAffected when the user is no longer in the expected status.
+func (p *postgresql) Transition(ctx context.Context, params *types.UpdateStatusParams) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET
+			status = @to,
+			is_member = @to = 'member',
+			internship_start_date = COALESCE(@internship_start_date, internship_start_date),
+			internship_end_date = COALESCE(@internship_end_date, internship_end_date),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = @id AND status = @from`,
+		pgx.NamedArgs{
+			"id":                    params.UserID,
+			"from":                  params.From,
+			"to":                    params.To,
+			"internship_start_date": params.InternshipStartDate,
+			"internship_end_date":   params.InternshipEndDate,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("updating status for id %d: %w", params.UserID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRowAffected
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_status_transitions ("user_id", "from_status", "to_status", "reason")
+		VALUES ($1, $2, $3, $4)`,
+		params.UserID, params.From, params.To, params.Reason,
+	); err != nil {
+		return fmt.Errorf("inserting status transition for id %d: %w", params.UserID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresql) ListTransitions(ctx context.Context, id uint64) ([]StatusTransition, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			id,
+			user_id,
+			from_status,
+			to_status,
+			reason,
+			created_at
+		FROM user_status_transitions
+		WHERE user_id = $1
+		ORDER BY created_at, id
+		LIMIT $2`, id, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting status transitions for id %d: %w", id, err)
+	}
+	transitions, err := pgx.CollectRows(rows, pgx.RowToStructByName[StatusTransition])
+	if err != nil {
+		return nil, fmt.Errorf("parsing status transitions: %w", err)
+	}
+	return transitions, nil
+}
+
+// SetAvatar points the user at a new set of avatar objects, returning the
+// path it replaced, if any, so they can be removed.
+func (p *postgresql) SetAvatar(ctx context.Context, id uint64, path *string) (*string, error) {
+	var previous *string
+	if err := p.conn.QueryRow(ctx, `
+		UPDATE users AS u
+		SET
+			avatar_path = $2,
+			updated_at = CURRENT_TIMESTAMP
+		FROM (SELECT avatar_path FROM users WHERE id = $1 FOR UPDATE) AS old
+		WHERE u.id = $1
+		RETURNING old.avatar_path`, id, path,
+	).Scan(&previous); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRow
+		}
+		return nil, fmt.Errorf("updating avatar for id %d: %w", id, err)
+	}
+	return previous, nil
+}
+
+// GetProfile returns an empty profile for users who never saved one, and
+// ErrNoRow only when the user does not exist.
+func (p *postgresql) GetProfile(ctx context.Context, id uint64) (Profile, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			COALESCE(p.bio, '') AS bio,
+			COALESCE(p.interests, '{}') AS interests,
+			COALESCE(p.links, '{}') AS links,
+			COALESCE(p.visibility, '{}') AS visibility,
+			COALESCE(p.name_source, 'admin') AS name_source
+		FROM users u
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE u.id = $1`, id)
+	if err != nil {
+		return Profile{}, fmt.Errorf("selecting profile for id %d: %w", id, err)
+	}
+	profile, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Profile])
+	if err != nil {
+		if errors.Is(pgx.ErrNoRows, err) {
+			return Profile{}, ErrNoRow
+		}
+		return Profile{}, fmt.Errorf("parsing profile: %w", err)
+	}
+	return profile, nil
+}
+
+// PutProfile also applies the recorded Google name right away when the user
+// switches to following it.
+func (p *postgresql) PutProfile(ctx context.Context, id uint64, profile *types.ProfileParams) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO profiles ("user_id", "bio", "interests", "links", "visibility", "name_source")
+		VALUES (@user_id, @bio, @interests, @links, @visibility, @name_source)
+		ON CONFLICT ("user_id") DO UPDATE
+		SET
+			bio = EXCLUDED.bio,
+			interests = EXCLUDED.interests,
+			links = EXCLUDED.links,
+			visibility = EXCLUDED.visibility,
+			name_source = EXCLUDED.name_source,
+			updated_at = CURRENT_TIMESTAMP`,
+		pgx.NamedArgs{
+			"user_id":     id,
+			"bio":         profile.Bio,
+			"interests":   profile.Interests,
+			"links":       profile.Links,
+			"visibility":  profile.Visibility,
+			"name_source": profile.NameSource,
+		},
+	); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNoRow
+		}
+		return fmt.Errorf("upserting profile for id %d: %w", id, err)
+	}
+	if profile.NameSource == types.NameSourceGoogle {
+		if _, err := tx.Exec(ctx, `
+			UPDATE users u
+			SET fullname = g.name, updated_at = CURRENT_TIMESTAMP
+			FROM google_profiles g
+			WHERE g.user_id = u.id AND u.id = $1 AND g.name <> ''`, id,
+		); err != nil {
+			return fmt.Errorf("applying google name for id %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresql) ListDirectory(ctx context.Context) ([]ProfiledUser, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			u.id,
+			u.email,
+			u.username,
+			u.fullname,
+			u.is_member,
+			u.internship_start_date,
+			u.status,
+			u.internship_end_date,
+			u.avatar_path,
+			COALESCE(p.bio, '') AS bio,
+			COALESCE(p.interests, '{}') AS interests,
+			COALESCE(p.links, '{}') AS links,
+			COALESCE(p.visibility, '{}') AS visibility,
+			COALESCE(p.name_source, 'admin') AS name_source
+		FROM users u
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE u.status = 'member'
+		ORDER BY u.fullname
+		LIMIT $1`, maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("selecting directory: %w", err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[ProfiledUser])
+	if err != nil {
+		return nil, fmt.Errorf("parsing directory: %w", err)
+	}
+	return users, nil
+}
+
+func (p *postgresql) GetGoogleProfile(ctx context.Context, id uint64) (GoogleProfile, error) {
+	rows, err := p.conn.Query(ctx, `
+		SELECT
+			name,
+			given_name,
+			family_name,
+			picture,
+			locale,
+			synced_at
+		FROM google_profiles
+		WHERE user_id = $1`, id)
+	if err != nil {
+		return GoogleProfile{}, fmt.Errorf("selecting google profile for id %d: %w", id, err)
+	}
+	profile, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[GoogleProfile])
+	if err != nil {
+		if errors.Is(pgx.ErrNoRows, err) {
+			return GoogleProfile{}, ErrNoRow
+		}
+		return GoogleProfile{}, fmt.Errorf("parsing google profile: %w", err)
+	}
+	return profile, nil
+}
+
+// SyncGoogleProfile records the profile Google reported at login and takes
+// its name as the full name of users who chose to follow Google's.
+func (p *postgresql) SyncGoogleProfile(
+	ctx context.Context,
+	id uint64,
+	profile *types.GoogleProfileParams,
+) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO google_profiles ("user_id", "name", "given_name", "family_name", "picture", "locale")
+		VALUES (@user_id, @name, @given_name, @family_name, @picture, @locale)
+		ON CONFLICT ("user_id") DO UPDATE
+		SET
+			name = EXCLUDED.name,
+			given_name = EXCLUDED.given_name,
+			family_name = EXCLUDED.family_name,
+			picture = EXCLUDED.picture,
+			locale = EXCLUDED.locale,
+			synced_at = CURRENT_TIMESTAMP`,
+		pgx.NamedArgs{
+			"user_id":     id,
+			"name":        profile.Name,
+			"given_name":  profile.GivenName,
+			"family_name": profile.FamilyName,
+			"picture":     profile.Picture,
+			"locale":      profile.Locale,
+		},
+	); err != nil {
+		pgErr := new(pgconn.PgError)
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNoRow
+		}
+		return fmt.Errorf("upserting google profile for id %d: %w", id, err)
+	}
+	if profile.Name != "" {
+		if _, err := tx.Exec(ctx, `
+			UPDATE users u
+			SET fullname = $2, updated_at = CURRENT_TIMESTAMP
+			FROM profiles p
+			WHERE p.user_id = u.id AND u.id = $1 AND p.name_source = 'google'`,
+			id, profile.Name,
+		); err != nil {
+			return fmt.Errorf("applying google name for id %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
 func (p *postgresql) Delete(ctx context.Context, id uint64) error {
 	_, err := p.conn.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
 	if err != nil {
@@ -173,3 +590,14 @@ func (p *postgresql) Delete(ctx context.Context, id uint64) error {
 	}
 	return nil
 }
+
+// statusOf derives the status from IsMember for params that leave it unset.
+func statusOf(user *types.CreateUserParams) types.Status {
+	if user.Status != "" {
+		return user.Status
+	}
+	if user.IsMember {
+		return types.StatusMember
+	}
+	return types.StatusIntern
+}
@@ -39,6 +39,14 @@ func TestMain(m *testing.M) {
 	defer conn.Close()
 	store = repository.NewUserPostgreSQL(conn)
 	code := m.Run()
+	_, err = conn.Exec(ctx, `DELETE FROM groups`)
+	if err != nil {
+		log.Fatalf("Failed to do cleanup task: %v\n", err)
+	}
+	_, err = conn.Exec(ctx, `DELETE FROM applications`)
+	if err != nil {
+		log.Fatalf("Failed to do cleanup task: %v\n", err)
+	}
 	_, err = conn.Exec(ctx, `DELETE FROM users`)
 	if err != nil {
 		log.Fatalf("Failed to do cleanup task: %v\n", err)
@@ -114,3 +122,129 @@ func TestListPassed(t *testing.T) {
 	_, err := store.ListPassed(ctx, 2024)
 	assert.Nil(t, err)
 }
+
+func TestListBatch(t *testing.T) {
+	ctx := context.Background()
+	users, err := store.ListBatch(
+		ctx,
+		[]uint64{0},
+		[]string{"jane.doe@example.com", "missing@example.com"},
+	)
+	assert.Nil(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	results, err := store.Search(ctx, "wonderlan", 20)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, results)
+}
+
+func TestTransition(t *testing.T) {
+	ctx := context.Background()
+	id, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "transition@example.com",
+		Username:            "transition",
+		Fullname:            "Transition User",
+		Status:              types.StatusIntern,
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	end := time.Now()
+	err = store.Transition(ctx, &types.UpdateStatusParams{
+		UserID:            id,
+		From:              types.StatusIntern,
+		To:                types.StatusMember,
+		Reason:            "finished internship",
+		InternshipEndDate: &end,
+	})
+	assert.Nil(t, err)
+	user, err := store.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.True(t, user.IsMember)
+	assert.Equal(t, string(types.StatusMember), user.Status)
+	err = store.Transition(ctx, &types.UpdateStatusParams{
+		UserID: id,
+		From:   types.StatusIntern,
+		To:     types.StatusMember,
+	})
+	assert.ErrorIs(t, err, repository.ErrNoRowAffected)
+	transitions, err := store.ListTransitions(ctx, id)
+	assert.Nil(t, err)
+	assert.Len(t, transitions, 1)
+}
+
+func TestListDue(t *testing.T) {
+	ctx := context.Background()
+	users, err := store.ListDue(ctx, types.StatusIntern, time.Now())
+	assert.Nil(t, err)
+	for _, user := range users {
+		assert.Equal(t, string(types.StatusIntern), user.Status)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	ctx := context.Background()
+	id, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "profile@example.com",
+		Username:            "profile",
+		Fullname:            "Profile User",
+		Status:              types.StatusMember,
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	profile, err := store.GetProfile(ctx, id)
+	assert.Nil(t, err)
+	assert.Empty(t, profile.Bio)
+	err = store.PutProfile(ctx, id, &types.ProfileParams{
+		Bio:        "networks",
+		Interests:  []string{"sdn", "iot"},
+		Links:      map[string]string{types.FieldGithub: "https://github.com/profile"},
+		Visibility: map[string]bool{types.FieldBio: true},
+		NameSource: types.NameSourceAdmin,
+	})
+	assert.Nil(t, err)
+	profile, err = store.GetProfile(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "networks", profile.Bio)
+	assert.Equal(t, []string{"sdn", "iot"}, profile.Interests)
+	assert.True(t, profile.Visibility[types.FieldBio])
+	users, err := store.ListDirectory(ctx)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, users)
+	_, err = store.GetProfile(ctx, 0)
+	assert.ErrorIs(t, err, repository.ErrNoRow)
+}
+
+func TestSyncGoogleProfile(t *testing.T) {
+	ctx := context.Background()
+	id, err := store.Create(ctx, &types.CreateUserParams{
+		Email:               "google@example.com",
+		Username:            "google",
+		Fullname:            "Admin Entered",
+		Status:              types.StatusMember,
+		InternshipStartDate: time.Now(),
+	})
+	assert.Nil(t, err)
+	params := &types.GoogleProfileParams{Name: "From Google", Locale: "id"}
+	assert.Nil(t, store.SyncGoogleProfile(ctx, id, params))
+	user, err := store.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Admin Entered", user.Fullname)
+	err = store.PutProfile(ctx, id, &types.ProfileParams{
+		Interests:  []string{},
+		Links:      map[string]string{},
+		Visibility: map[string]bool{},
+		NameSource: types.NameSourceGoogle,
+	})
+	assert.Nil(t, err)
+	user, err = store.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "From Google", user.Fullname)
+	google, err := store.GetGoogleProfile(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "id", google.Locale)
+	err = store.SyncGoogleProfile(ctx, 0, params)
+	assert.ErrorIs(t, err, repository.ErrNoRow)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type s3 struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewObjectS3 keeps objects in a bucket of any S3-compatible storage. Objects
+// are linked under baseURL, or straight from the endpoint when it is empty.
+func NewObjectS3(
+	endpoint, accessKey, secretKey, region, bucket, baseURL string,
+	useSSL bool,
+) (IObjectStorage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating s3 client for %s: %w", endpoint, err)
+	}
+	if baseURL == "" {
+		scheme := "http"
+		if useSSL {
+			scheme = "https"
+		}
+		baseURL = fmt.Sprintf("%s://%s/%s", scheme, endpoint, bucket)
+	}
+	return &s3{client, bucket, strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (s *s3) Put(
+	ctx context.Context,
+	key string,
+	r io.Reader,
+	size int64,
+	contentType string,
+) error {
+	if _, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	}); err != nil {
+		return fmt.Errorf("putting object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("removing object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// s3Object is an object kept by newStubS3.
+type s3Object struct {
+	body        string
+	contentType string
+}
+
+// newStubS3 answers the single part uploads and deletes of an S3 bucket,
+// keeping objects by path.
+func newStubS3(t *testing.T) (string, map[string]s3Object, *sync.Mutex) {
+	var mu sync.Mutex
+	objects := map[string]s3Object{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, err := readS3Body(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			objects[r.URL.Path] = s3Object{string(body), r.Header.Get("Content-Type")}
+			w.Header().Set("ETag", `"stub"`)
+			w.WriteHeader(http.StatusOK)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), objects, &mu
+}
+
+// readS3Body reads the payload of an upload, unwrapping the signed chunks
+// clients stream it in over plain http.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var body []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		header, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		n, err := strconv.ParseInt(size, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return body, nil
+		}
+		chunk := make([]byte, n+2) // the chunk is followed by CRLF
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		body = append(body, chunk[:n]...)
+	}
+}
+
+func TestObjectS3(t *testing.T) {
+	ctx := context.Background()
+	endpoint, objects, mu := newStubS3(t)
+	objs, err := repository.NewObjectS3(endpoint, "access", "secret", "us-east-1", "icn", "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://"+endpoint+"/icn/avatars/1/64.jpg", objs.URL("avatars/1/64.jpg"))
+
+	assert.Nil(t, objs.Put(ctx, "avatars/1/64.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"))
+	mu.Lock()
+	assert.Equal(t, s3Object{"jpeg", "image/jpeg"}, objects["/icn/avatars/1/64.jpg"])
+	mu.Unlock()
+
+	assert.Nil(t, objs.Delete(ctx, "avatars/1/64.jpg"))
+	mu.Lock()
+	assert.Empty(t, objects)
+	mu.Unlock()
+
+	objs, err = repository.NewObjectS3(endpoint, "access", "secret", "us-east-1", "icn", "https://cdn.example.com/", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://cdn.example.com/avatars/1/64.jpg", objs.URL("avatars/1/64.jpg"), "links go through baseURL")
+}
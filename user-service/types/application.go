@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+type ApplicationStatus string
+
+const (
+	ApplicationPending  ApplicationStatus = "pending"
+	ApplicationApproved ApplicationStatus = "approved"
+	ApplicationRejected ApplicationStatus = "rejected"
+)
+
+type Application struct {
+	ID                  uint64            `json:"id"`
+	Email               string            `json:"email"`
+	Username            string            `json:"username"`
+	Fullname            string            `json:"fullname"`
+	Motivation          string            `json:"motivation"`
+	InternshipStartDate time.Time         `json:"internshipStartDate"`
+	Status              ApplicationStatus `json:"status"`
+	Comment             string            `json:"comment"`
+	UserID              *uint64           `json:"userId"`
+	ReviewedAt          *time.Time        `json:"reviewedAt"`
+	CreatedAt           time.Time         `json:"createdAt"`
+}
+
+type CreateApplicationParams struct {
+	Email               string    `json:"email" validate:"required,email"`
+	Username            string    `json:"username" validate:"required,max=64"`
+	Fullname            string    `json:"fullname" validate:"required,max=128"`
+	Motivation          string    `json:"motivation" validate:"required,max=4096"`
+	InternshipStartDate time.Time `json:"internshipStartDate" validate:"required"`
+}
+
+type ReviewApplicationParams struct {
+	Comment string `json:"comment" validate:"max=4096"`
+	// InternshipStartDate overrides the date the candidate asked for
+	InternshipStartDate *time.Time `json:"internshipStartDate"`
+}
+
+type UpdateApplicationParams struct {
+	ID      uint64
+	Status  ApplicationStatus
+	Comment string
+	UserID  *uint64
+}
@@ -0,0 +1,38 @@
+package types
+
+type GroupRole string
+
+const (
+	RoleLead   GroupRole = "lead"
+	RoleMember GroupRole = "member"
+)
+
+type Group struct {
+	ID          uint64        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Members     []GroupMember `json:"members,omitempty"`
+}
+
+type GroupMember struct {
+	UserID   uint64    `json:"userId"`
+	Username string    `json:"username"`
+	Fullname string    `json:"fullname"`
+	Role     GroupRole `json:"role"`
+}
+
+// UserGroup is a group seen from one of its members.
+type UserGroup struct {
+	ID   uint64    `json:"id"`
+	Name string    `json:"name"`
+	Role GroupRole `json:"role"`
+}
+
+type GroupParams struct {
+	Name        string `json:"name" validate:"required,max=128"`
+	Description string `json:"description" validate:"max=4096"`
+}
+
+type GroupMemberParams struct {
+	Role GroupRole `json:"role" validate:"required,oneof=lead member"`
+}
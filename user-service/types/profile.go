@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+// Profile fields a user may mark public in the directory.
+const (
+	FieldFullname  = "fullname"
+	FieldUsername  = "username"
+	FieldEmail     = "email"
+	FieldAvatar    = "avatar"
+	FieldBio       = "bio"
+	FieldInterests = "interests"
+	FieldGithub    = "github"
+	FieldLinkedin  = "linkedin"
+	FieldWebsite   = "website"
+)
+
+// NameSource tells whether a user's full name is the one entered by an admin
+// or the one Google reports at every login.
+type NameSource string
+
+const (
+	NameSourceAdmin  NameSource = "admin"
+	NameSourceGoogle NameSource = "google"
+)
+
+type Profile struct {
+	Bio       string            `json:"bio"`
+	Interests []string          `json:"interests"`
+	Links     map[string]string `json:"links"`
+	// Visibility tells for every field whether the directory shows it
+	Visibility map[string]bool `json:"visibility"`
+	NameSource NameSource      `json:"nameSource"`
+	// Google is the profile recorded at the last login, absent before one
+	Google *GoogleProfile `json:"google,omitempty"`
+}
+
+type ProfileParams struct {
+	Bio        string            `json:"bio" validate:"max=2048"`
+	Interests  []string          `json:"interests" validate:"max=20,dive,required,max=64"`
+	Links      map[string]string `json:"links" validate:"dive,keys,oneof=github linkedin website,endkeys,omitempty,url,max=256"`
+	Visibility map[string]bool   `json:"visibility" validate:"dive,keys,oneof=fullname username email avatar bio interests github linkedin website,endkeys"`
+	NameSource NameSource        `json:"nameSource" validate:"omitempty,oneof=admin google"`
+}
+
+type GoogleProfile struct {
+	Name       string    `json:"name"`
+	GivenName  string    `json:"givenName"`
+	FamilyName string    `json:"familyName"`
+	Picture    string    `json:"picture"`
+	Locale     string    `json:"locale"`
+	SyncedAt   time.Time `json:"syncedAt"`
+}
+
+type GoogleProfileParams struct {
+	Name       string `json:"name" validate:"max=255"`
+	GivenName  string `json:"givenName" validate:"max=255"`
+	FamilyName string `json:"familyName" validate:"max=255"`
+	Picture    string `json:"picture" validate:"omitempty,url"`
+	Locale     string `json:"locale" validate:"max=35"`
+}
+
+// DirectoryEntry is a member as the public sees them, fields they keep
+// private are left empty.
+type DirectoryEntry struct {
+	Fullname  string            `json:"fullname,omitempty"`
+	Username  string            `json:"username,omitempty"`
+	Email     string            `json:"email,omitempty"`
+	AvatarURL string            `json:"avatarUrl,omitempty"`
+	Bio       string            `json:"bio,omitempty"`
+	Interests []string          `json:"interests,omitempty"`
+	Links     map[string]string `json:"links,omitempty"`
+}
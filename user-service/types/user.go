@@ -2,13 +2,28 @@ package types
 
 import "time"
 
+type Status string
+
+const (
+	StatusApplicant Status = "applicant"
+	StatusIntern    Status = "intern"
+	StatusMember    Status = "member"
+	StatusAlumni    Status = "alumni"
+)
+
 type User struct {
-	ID                  uint64    `json:"id"`
-	Email               string    `json:"email"`
-	Username            string    `json:"username"`
-	Fullname            string    `json:"fullname"`
-	IsMember            bool      `json:"isMember"`
-	InternshipStartDate time.Time `json:"internshipStartDate"`
+	ID                  uint64     `json:"id"`
+	Email               string     `json:"email"`
+	Username            string     `json:"username"`
+	Fullname            string     `json:"fullname"`
+	IsMember            bool       `json:"isMember"`
+	Status              Status     `json:"status"`
+	InternshipStartDate time.Time  `json:"internshipStartDate"`
+	InternshipEndDate   *time.Time `json:"internshipEndDate"`
+	AvatarURL           string     `json:"avatarUrl,omitempty"`
+	// AvatarURLs holds the avatar resized to each standard size, keyed by
+	// its width in pixels
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty"`
 }
 
 type CreateUserParams struct {
@@ -16,5 +31,50 @@ type CreateUserParams struct {
 	Username            string
 	Fullname            string
 	IsMember            bool
+	Status              Status
 	InternshipStartDate time.Time
 }
+
+type TransitionParams struct {
+	Reason string     `json:"reason"`
+	Date   *time.Time `json:"date"`
+}
+
+type UpdateStatusParams struct {
+	UserID              uint64
+	From                Status
+	To                  Status
+	Reason              string
+	InternshipStartDate *time.Time
+	InternshipEndDate   *time.Time
+}
+
+type StatusTransition struct {
+	ID         uint64    `json:"id"`
+	UserID     uint64    `json:"userId"`
+	FromStatus Status    `json:"fromStatus"`
+	ToStatus   Status    `json:"toStatus"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type BatchGetUsersParams struct {
+	IDs    []uint64 `json:"ids"`
+	Emails []string `json:"emails" validate:"dive,email"`
+}
+
+type BatchUsers struct {
+	Users   []User       `json:"users"`
+	Missing BatchMissing `json:"missing"`
+}
+
+type BatchMissing struct {
+	IDs    []uint64 `json:"ids"`
+	Emails []string `json:"emails"`
+}
+
+type UserSearchResult struct {
+	User
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
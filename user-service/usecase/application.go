@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+)
+
+type IApplicationUsecase interface {
+	Apply(
+		ctx context.Context,
+		application *types.CreateApplicationParams,
+	) (types.Application, error)
+	FetchQueue(
+		ctx context.Context,
+		status types.ApplicationStatus,
+	) ([]types.Application, error)
+	Approve(
+		ctx context.Context,
+		id uint64,
+		params *types.ReviewApplicationParams,
+	) (types.Application, error)
+	Reject(
+		ctx context.Context,
+		id uint64,
+		params *types.ReviewApplicationParams,
+	) (types.Application, error)
+}
+
+type applicationUsecase struct {
+	store repository.IApplicationStorage
+}
+
+func NewApplicationUsecase(store repository.IApplicationStorage) IApplicationUsecase {
+	return &applicationUsecase{store}
+}
+
+func (u *applicationUsecase) Apply(
+	ctx context.Context,
+	application *types.CreateApplicationParams,
+) (types.Application, error) {
+	id, err := u.store.Create(ctx, application)
+	if err != nil {
+		if errors.Is(repository.ErrDuplicateRow, err) {
+			return types.Application{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgApplicationPending,
+			}
+		}
+		return types.Application{}, fmt.Errorf("create application: %w", err)
+	}
+	return u.fetch(ctx, id)
+}
+
+func (u *applicationUsecase) FetchQueue(
+	ctx context.Context,
+	status types.ApplicationStatus,
+) ([]types.Application, error) {
+	if status == "" {
+		status = types.ApplicationPending
+	}
+	applications, err := u.store.List(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("fetch applications: %w", err)
+	}
+	dtos := make([]types.Application, len(applications))
+	for i, application := range applications {
+		dtos[i] = application.DTO()
+	}
+	return dtos, nil
+}
+
+// Approve registers the candidate as an intern starting on the date they
+// asked for, unless the reviewer gives another one.
+func (u *applicationUsecase) Approve(
+	ctx context.Context,
+	id uint64,
+	params *types.ReviewApplicationParams,
+) (types.Application, error) {
+	application, err := u.fetchPending(ctx, id)
+	if err != nil {
+		return types.Application{}, err
+	}
+	start := application.InternshipStartDate
+	if params.InternshipStartDate != nil {
+		start = *params.InternshipStartDate
+	}
+	user := &types.CreateUserParams{
+		Email:               application.Email,
+		Username:            application.Username,
+		Fullname:            application.Fullname,
+		Status:              types.StatusIntern,
+		InternshipStartDate: start,
+	}
+	if err := normalizeStatus(user); err != nil {
+		return types.Application{}, err
+	}
+	// The user and the review are stored together, so a failure leaves the
+	// application pending to approve again.
+	if _, err := u.store.Approve(ctx, &types.UpdateApplicationParams{
+		ID:      id,
+		Status:  types.ApplicationApproved,
+		Comment: params.Comment,
+	}, user); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateRow):
+			return types.Application{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgUserExist,
+			}
+		case errors.Is(err, repository.ErrNoRowAffected):
+			return types.Application{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgApplicationReviewed,
+			}
+		}
+		return types.Application{}, fmt.Errorf("approve application: %w", err)
+	}
+	return u.fetch(ctx, id)
+}
+
+func (u *applicationUsecase) Reject(
+	ctx context.Context,
+	id uint64,
+	params *types.ReviewApplicationParams,
+) (types.Application, error) {
+	if _, err := u.fetchPending(ctx, id); err != nil {
+		return types.Application{}, err
+	}
+	return u.review(ctx, &types.UpdateApplicationParams{
+		ID:      id,
+		Status:  types.ApplicationRejected,
+		Comment: params.Comment,
+	})
+}
+
+func (u *applicationUsecase) review(
+	ctx context.Context,
+	params *types.UpdateApplicationParams,
+) (types.Application, error) {
+	if err := u.store.Review(ctx, params); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return types.Application{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgApplicationReviewed,
+			}
+		}
+		return types.Application{}, fmt.Errorf("review application: %w", err)
+	}
+	return u.fetch(ctx, params.ID)
+}
+
+func (u *applicationUsecase) fetchPending(ctx context.Context, id uint64) (types.Application, error) {
+	application, err := u.fetch(ctx, id)
+	if err != nil {
+		return types.Application{}, err
+	}
+	if application.Status != types.ApplicationPending {
+		return types.Application{}, &Error{
+			Code:    http.StatusConflict,
+			Message: msgApplicationReviewed,
+		}
+	}
+	return application, nil
+}
+
+func (u *applicationUsecase) fetch(ctx context.Context, id uint64) (types.Application, error) {
+	application, err := u.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.Application{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgApplicationNotFound,
+			}
+		}
+		return types.Application{}, fmt.Errorf("fetch application by id: %w", err)
+	}
+	return application.DTO(), nil
+}
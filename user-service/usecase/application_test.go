@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprove(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewUserFake()
+	u := NewApplicationUsecase(repository.NewApplicationFake(users))
+	start := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	code := func(err error) int {
+		uscErr := new(Error)
+		assert.True(t, errors.As(err, &uscErr))
+		return uscErr.Code
+	}
+
+	taken := newUserWithStatus(t, users, types.StatusMember)
+	application, err := u.Apply(ctx, &types.CreateApplicationParams{
+		Email:               "candidate@example.com",
+		Username:            "member",
+		Fullname:            "Candidate",
+		Motivation:          "networking",
+		InternshipStartDate: start,
+	})
+	assert.Nil(t, err)
+	_, err = u.Approve(ctx, application.ID, &types.ReviewApplicationParams{})
+	assert.Equal(t, http.StatusConflict, code(err), "the username is taken")
+	pending, err := u.FetchQueue(ctx, "")
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1, "a failed approval leaves the application pending")
+
+	assert.Nil(t, users.Delete(ctx, taken))
+	approved, err := u.Approve(ctx, application.ID, &types.ReviewApplicationParams{Comment: "welcome"})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ApplicationApproved, approved.Status)
+	assert.Equal(t, "welcome", approved.Comment)
+	user, err := users.Get(ctx, *approved.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, "candidate@example.com", user.Email)
+	assert.Equal(t, string(types.StatusIntern), user.Status)
+	assert.Equal(t, start, user.InternshipStartDate)
+
+	_, err = u.Approve(ctx, application.ID, &types.ReviewApplicationParams{})
+	assert.Equal(t, http.StatusConflict, code(err))
+	_, err = u.Reject(ctx, 404, &types.ReviewApplicationParams{})
+	assert.Equal(t, http.StatusNotFound, code(err))
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxAvatarPixels rejects images whose decoded size would dwarf the
+	// upload, a 4096x4096 image being the largest accepted
+	maxAvatarPixels = 4096 * 4096
+	avatarQuality   = 85
+	// avatarSize is the size linked as the user's main avatar URL
+	avatarSize = 256
+)
+
+// avatarSizes are the square widths, in pixels, every avatar is resized to.
+var avatarSizes = []int{64, 256, 512}
+
+var avatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// UploadAvatar stores the image center-cropped and resized into every
+// standard size, then removes the avatar it replaces.
+func (u *usecase) UploadAvatar(
+	ctx context.Context,
+	id uint64,
+	fileheader *multipart.FileHeader,
+) (types.User, error) {
+	file, err := fileheader.Open()
+	if err != nil {
+		return types.User{}, fmt.Errorf("open avatar file header: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			u.log.Error().Err(err).Msg("closing file buffer")
+		}
+	}()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return types.User{}, fmt.Errorf("read avatar file content: %w", err)
+	}
+	if !avatarTypes[http.DetectContentType(content)] {
+		return types.User{}, &Error{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: msgAvatarType,
+		}
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || cfg.Width*cfg.Height > maxAvatarPixels {
+		return types.User{}, &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgAvatarImage,
+			Err:     err,
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return types.User{}, &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgAvatarImage,
+			Err:     err,
+		}
+	}
+	path := fmt.Sprintf("avatars/%d/%d", id, time.Now().UnixNano())
+	for _, size := range avatarSizes {
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, resize(img, size), &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return types.User{}, fmt.Errorf("encode avatar of size %d: %w", size, err)
+		}
+		if err := u.objects.Put(
+			ctx,
+			avatarKey(path, size),
+			buf,
+			int64(buf.Len()),
+			"image/jpeg",
+		); err != nil {
+			return types.User{}, fmt.Errorf("store avatar of size %d: %w", size, err)
+		}
+	}
+	previous, err := u.store.SetAvatar(ctx, id, &path)
+	if err != nil {
+		u.deleteAvatar(ctx, path)
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.User{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return types.User{}, fmt.Errorf("set avatar: %w", err)
+	}
+	if previous != nil {
+		u.deleteAvatar(ctx, *previous)
+	}
+	return u.Fetch(ctx, id)
+}
+
+func (u *usecase) DeleteAvatar(ctx context.Context, id uint64) error {
+	previous, err := u.store.SetAvatar(ctx, id, nil)
+	if err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return fmt.Errorf("unset avatar: %w", err)
+	}
+	if previous != nil {
+		u.deleteAvatar(ctx, *previous)
+	}
+	return nil
+}
+
+// deleteAvatar removes every size of an avatar no longer linked, failures
+// only leave orphaned objects behind so they are logged and not returned.
+func (u *usecase) deleteAvatar(ctx context.Context, path string) {
+	for _, size := range avatarSizes {
+		if err := u.objects.Delete(ctx, avatarKey(path, size)); err != nil {
+			u.log.Error().Err(err).Str("path", path).Msg("deleting avatar")
+		}
+	}
+}
+
+// dto converts the user, resolving its avatar path to public URLs.
+func (u *usecase) dto(user repository.User) types.User {
+	dto := user.DTO()
+	if user.AvatarPath == nil {
+		return dto
+	}
+	dto.AvatarURLs = make(map[string]string, len(avatarSizes))
+	for _, size := range avatarSizes {
+		dto.AvatarURLs[strconv.Itoa(size)] = u.objects.URL(avatarKey(*user.AvatarPath, size))
+	}
+	dto.AvatarURL = dto.AvatarURLs[strconv.Itoa(avatarSize)]
+	return dto
+}
+
+func avatarKey(path string, size int) string {
+	return fmt.Sprintf("%s-%d.jpg", path, size)
+}
+
+// resize crops the largest centered square out of img and scales it to
+// size, flattening transparency onto white as JPEG has none.
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+	return dst
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 300, 100))
+	for x := 100; x < 200; x++ {
+		for y := 0; y < 100; y++ {
+			src.Set(x, y, color.Black)
+		}
+	}
+	for _, size := range avatarSizes {
+		dst := resize(src, size)
+		assert.Equal(t, image.Rect(0, 0, size, size), dst.Bounds())
+		// only the black center square survives the crop
+		r, g, b, _ := dst.At(0, 0).RGBA()
+		assert.Equal(t, uint32(0), r+g+b)
+	}
+}
+
+func TestResizeTransparent(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	dst := resize(src, 64)
+	assert.Equal(t, color.RGBAModel.Convert(color.White), dst.At(32, 32))
+}
@@ -18,6 +18,22 @@ func (e Error) Error() string {
 }
 
 const (
-	msgUserExist    = "user already exist"
-	msgUserNotFound = "user not found"
+	msgUserExist         = "user already exist"
+	msgUserNotFound      = "user not found"
+	msgBatchSize         = "batch must hold between 1 and 100 ids and emails"
+	msgQueryTooShort     = "search query must be at least 2 characters"
+	msgInvalidTransition = "transition not allowed from status %s"
+	msgStatusChanged     = "user status changed concurrently"
+	msgUnknownStatus     = "unknown status %s"
+
+	msgApplicationPending  = "application already pending for this email"
+	msgApplicationNotFound = "application not found"
+	msgApplicationReviewed = "application already reviewed"
+
+	msgGroupExist          = "group already exist"
+	msgGroupNotFound       = "group not found"
+	msgGroupOrUserNotFound = "group or user not found"
+
+	msgAvatarType  = "avatar must be a jpeg, png or webp image"
+	msgAvatarImage = "avatar image unreadable or larger than 4096x4096"
 )
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+)
+
+type IGroupUsecase interface {
+	Create(ctx context.Context, group *types.GroupParams) (types.Group, error)
+	FetchList(ctx context.Context) ([]types.Group, error)
+	Fetch(ctx context.Context, id uint64) (types.Group, error)
+	Update(ctx context.Context, id uint64, group *types.GroupParams) (types.Group, error)
+	Delete(ctx context.Context, id uint64) error
+	PutMember(ctx context.Context, id, userID uint64, role types.GroupRole) error
+	DeleteMember(ctx context.Context, id, userID uint64) error
+	FetchByUser(ctx context.Context, userID uint64) ([]types.UserGroup, error)
+}
+
+type groupUsecase struct {
+	store repository.IGroupStorage
+}
+
+func NewGroupUsecase(store repository.IGroupStorage) IGroupUsecase {
+	return &groupUsecase{store}
+}
+
+func (u *groupUsecase) Create(ctx context.Context, group *types.GroupParams) (types.Group, error) {
+	id, err := u.store.Create(ctx, group)
+	if err != nil {
+		if errors.Is(repository.ErrDuplicateRow, err) {
+			return types.Group{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgGroupExist,
+			}
+		}
+		return types.Group{}, fmt.Errorf("create group: %w", err)
+	}
+	return types.Group{
+		ID:          id,
+		Name:        group.Name,
+		Description: group.Description,
+	}, nil
+}
+
+func (u *groupUsecase) FetchList(ctx context.Context) ([]types.Group, error) {
+	groups, err := u.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch groups: %w", err)
+	}
+	dtos := make([]types.Group, len(groups))
+	for i, group := range groups {
+		dtos[i] = group.DTO()
+	}
+	return dtos, nil
+}
+
+func (u *groupUsecase) Fetch(ctx context.Context, id uint64) (types.Group, error) {
+	group, err := u.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.Group{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgGroupNotFound,
+			}
+		}
+		return types.Group{}, fmt.Errorf("fetch group by id: %w", err)
+	}
+	members, err := u.store.ListMembers(ctx, id)
+	if err != nil {
+		return types.Group{}, fmt.Errorf("fetch group members: %w", err)
+	}
+	dto := group.DTO()
+	dto.Members = make([]types.GroupMember, len(members))
+	for i, member := range members {
+		dto.Members[i] = member.DTO()
+	}
+	return dto, nil
+}
+
+func (u *groupUsecase) Update(
+	ctx context.Context,
+	id uint64,
+	group *types.GroupParams,
+) (types.Group, error) {
+	if err := u.store.Update(ctx, id, group); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateRow):
+			return types.Group{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgGroupExist,
+			}
+		case errors.Is(err, repository.ErrNoRowAffected):
+			return types.Group{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgGroupNotFound,
+			}
+		}
+		return types.Group{}, fmt.Errorf("update group: %w", err)
+	}
+	return u.Fetch(ctx, id)
+}
+
+func (u *groupUsecase) Delete(ctx context.Context, id uint64) error {
+	return u.store.Delete(ctx, id)
+}
+
+func (u *groupUsecase) PutMember(
+	ctx context.Context,
+	id, userID uint64,
+	role types.GroupRole,
+) error {
+	if err := u.store.PutMember(ctx, id, userID, role); err != nil {
+		if errors.Is(err, repository.ErrNoRow) {
+			return &Error{
+				Code:    http.StatusNotFound,
+				Message: msgGroupOrUserNotFound,
+			}
+		}
+		return fmt.Errorf("put group member: %w", err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) DeleteMember(ctx context.Context, id, userID uint64) error {
+	return u.store.DeleteMember(ctx, id, userID)
+}
+
+func (u *groupUsecase) FetchByUser(ctx context.Context, userID uint64) ([]types.UserGroup, error) {
+	groups, err := u.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch user groups: %w", err)
+	}
+	dtos := make([]types.UserGroup, len(groups))
+	for i, group := range groups {
+		dtos[i] = group.DTO()
+	}
+	return dtos, nil
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+)
+
+// defaultVisibility applies to fields a user never marked, members stay out
+// of the directory until they choose to show something.
+var defaultVisibility = map[string]bool{
+	types.FieldFullname:  false,
+	types.FieldUsername:  false,
+	types.FieldEmail:     false,
+	types.FieldAvatar:    false,
+	types.FieldBio:       false,
+	types.FieldInterests: false,
+	types.FieldGithub:    false,
+	types.FieldLinkedin:  false,
+	types.FieldWebsite:   false,
+}
+
+func (u *usecase) FetchProfile(ctx context.Context, id uint64) (types.Profile, error) {
+	profile, err := u.store.GetProfile(ctx, id)
+	if err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.Profile{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return types.Profile{}, fmt.Errorf("fetch profile: %w", err)
+	}
+	dto := profile.DTO()
+	dto.Visibility = visibility(dto.Visibility)
+	google, err := u.store.GetGoogleProfile(ctx, id)
+	if err != nil && !errors.Is(repository.ErrNoRow, err) {
+		return types.Profile{}, fmt.Errorf("fetch google profile: %w", err)
+	}
+	if err == nil {
+		googleDTO := google.DTO()
+		dto.Google = &googleDTO
+	}
+	return dto, nil
+}
+
+func (u *usecase) UpdateProfile(
+	ctx context.Context,
+	id uint64,
+	params *types.ProfileParams,
+) (types.Profile, error) {
+	if params.Interests == nil {
+		params.Interests = []string{}
+	}
+	links := make(map[string]string, len(params.Links))
+	for name, link := range params.Links {
+		if link != "" {
+			links[name] = link
+		}
+	}
+	params.Links = links
+	params.Visibility = visibility(params.Visibility)
+	if params.NameSource == "" {
+		params.NameSource = types.NameSourceAdmin
+	}
+	if err := u.store.PutProfile(ctx, id, params); err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.Profile{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return types.Profile{}, fmt.Errorf("update profile: %w", err)
+	}
+	return u.FetchProfile(ctx, id)
+}
+
+func (u *usecase) SyncGoogle(
+	ctx context.Context,
+	id uint64,
+	params *types.GoogleProfileParams,
+) error {
+	if err := u.store.SyncGoogleProfile(ctx, id, params); err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return fmt.Errorf("sync google profile: %w", err)
+	}
+	return nil
+}
+
+func (u *usecase) FetchDirectory(ctx context.Context) ([]types.DirectoryEntry, error) {
+	users, err := u.store.ListDirectory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch directory: %w", err)
+	}
+	entries := []types.DirectoryEntry{}
+	for _, user := range users {
+		dto := u.dto(user.User)
+		public := visibility(user.Visibility)
+		entry := types.DirectoryEntry{}
+		if public[types.FieldFullname] {
+			entry.Fullname = dto.Fullname
+		}
+		if public[types.FieldUsername] {
+			entry.Username = dto.Username
+		}
+		if public[types.FieldEmail] {
+			entry.Email = dto.Email
+		}
+		if public[types.FieldAvatar] {
+			entry.AvatarURL = dto.AvatarURL
+		}
+		if public[types.FieldBio] {
+			entry.Bio = user.Bio
+		}
+		if public[types.FieldInterests] {
+			entry.Interests = user.Interests
+		}
+		for name, link := range user.Links {
+			if public[name] {
+				if entry.Links == nil {
+					entry.Links = map[string]string{}
+				}
+				entry.Links[name] = link
+			}
+		}
+		if entry.Fullname == "" && entry.Username == "" && entry.Email == "" &&
+			entry.AvatarURL == "" && entry.Bio == "" && len(entry.Interests) == 0 && len(entry.Links) == 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// visibility completes the marked fields with the default for the rest.
+func visibility(marked map[string]bool) map[string]bool {
+	complete := make(map[string]bool, len(defaultVisibility))
+	for field, public := range defaultVisibility {
+		complete[field] = public
+	}
+	for field, public := range marked {
+		if _, ok := complete[field]; ok {
+			complete[field] = public
+		}
+	}
+	return complete
+}
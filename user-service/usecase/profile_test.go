@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchDirectory(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewUserFake()
+	u := newUserUsecase(store)
+	member := func(name string, profile *types.ProfileParams) {
+		id, err := store.Create(ctx, &types.CreateUserParams{
+			Email:               name + "@example.com",
+			Username:            name,
+			Fullname:            name,
+			IsMember:            true,
+			InternshipStartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Status:              types.StatusMember,
+		})
+		assert.Nil(t, err)
+		if profile != nil {
+			assert.Nil(t, store.PutProfile(ctx, id, profile))
+		}
+	}
+	member("anna", nil)
+	member("bram", &types.ProfileParams{
+		Bio:       "networks",
+		Interests: []string{"bgp"},
+		Links:     map[string]string{"github": "https://github.com/bram", "website": "https://bram.example.com"},
+		Visibility: map[string]bool{
+			types.FieldFullname: true,
+			types.FieldBio:      true,
+			types.FieldGithub:   true,
+		},
+	})
+	member("cleo", &types.ProfileParams{
+		Bio:        "secret",
+		Visibility: map[string]bool{types.FieldEmail: true, types.FieldBio: false},
+	})
+	member("dani", &types.ProfileParams{
+		Bio:        "hidden",
+		Visibility: map[string]bool{},
+	})
+	newUserWithStatus(t, store, types.StatusIntern)
+
+	entries, err := u.FetchDirectory(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []types.DirectoryEntry{
+		{
+			Fullname: "bram",
+			Bio:      "networks",
+			Links:    map[string]string{"github": "https://github.com/bram"},
+		},
+		{Email: "cleo@example.com"},
+	}, entries, "every field is private until marked, members showing nothing are left out")
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// fuzzyThreshold is the trigram similarity a word needs to a query term to
+// count as a misspelling of it.
+const fuzzyThreshold = 0.4
+
+// highlight escapes text for HTML and wraps every word matching a term of
+// query in <mark> tags, whether it matched the full-text or fuzzy search.
+// Markers are only ever added around escaped text, so user-controlled names
+// cannot inject markup.
+func highlight(text, query string) string {
+	terms := []string{}
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		// excluded words matched nothing
+		if strings.HasPrefix(term, "-") {
+			continue
+		}
+		term = strings.Trim(term, `"`)
+		if term != "" && term != "or" {
+			terms = append(terms, term)
+		}
+	}
+	var b strings.Builder
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for len(text) > 0 {
+		end := strings.IndexFunc(text, func(r rune) bool { return !isWord(r) })
+		if end == 0 {
+			end = strings.IndexFunc(text, isWord)
+			if end < 0 {
+				end = len(text)
+			}
+			b.WriteString(html.EscapeString(text[:end]))
+			text = text[end:]
+			continue
+		}
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		if matches(strings.ToLower(word), terms) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		text = text[end:]
+	}
+	return b.String()
+}
+
+func matches(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(word, term) || similarity(word, term) >= fuzzyThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// similarity is the trigram similarity of pg_trgm, the share of trigrams of
+// both words padded with spaces they have in common.
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	total := len(ta) + len(tb) - shared
+	if total == 0 {
+		return 0
+	}
+	return float64(shared) / float64(total)
+}
+
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
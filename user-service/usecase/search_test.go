@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text, query, want string
+	}{
+		"full-text match": {
+			"Ada Lovelace ada ada@example.com", "lovelace",
+			"Ada <mark>Lovelace</mark> ada ada@example.com",
+		},
+		"every term": {
+			"Ada Lovelace ada ada@example.com", "ada lovelace",
+			"<mark>Ada</mark> <mark>Lovelace</mark> <mark>ada</mark> <mark>ada</mark>@example.com",
+		},
+		"fuzzy match": {
+			"Grace Hopper grace grace@example.com", "hoper",
+			"Grace <mark>Hopper</mark> grace grace@example.com",
+		},
+		"markup escaped": {
+			`<img src=x onerror=alert(1)> mallory mallory@example.com`, "mallory",
+			"&lt;img src=x onerror=alert(1)&gt; <mark>mallory</mark> <mark>mallory</mark>@example.com",
+		},
+		"markup in a match escaped": {
+			"<b>Eve</b> eve eve@example.com", "eve",
+			"&lt;b&gt;<mark>Eve</mark>&lt;/b&gt; <mark>eve</mark> <mark>eve</mark>@example.com",
+		},
+		"operators ignored": {
+			"Alan Turing alan alan@example.com", `"turing" or -alan`,
+			"Alan <mark>Turing</mark> alan alan@example.com",
+		},
+	} {
+		assert.Equal(t, tc.want, highlight(tc.text, tc.query), name)
+	}
+}
@@ -8,13 +8,24 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Lab-ICN/backend/user-service/repository"
 	"github.com/Lab-ICN/backend/user-service/types"
 	"github.com/rs/zerolog"
 )
 
+const (
+	// maxBatchSize bounds how many ids and emails one batch lookup may carry.
+	maxBatchSize = 100
+	// searchLimit bounds how many ranked results one search returns.
+	searchLimit = 20
+	// minQueryLength keeps searches from matching most of the table.
+	minQueryLength = 2
+)
+
 const (
 	colEmail uint = iota
 	colUsername
@@ -33,25 +44,67 @@ type IUserUsecase interface {
 		fileheader *multipart.FileHeader,
 	) error
 	Fetch(ctx context.Context, id uint64) (types.User, error)
+	FetchByEmail(ctx context.Context, email string) (types.User, error)
+	FetchList(ctx context.Context) ([]types.User, error)
+	FetchBatch(
+		ctx context.Context,
+		params *types.BatchGetUsersParams,
+	) (types.BatchUsers, error)
+	Search(ctx context.Context, query string) ([]types.UserSearchResult, error)
+	Promote(
+		ctx context.Context,
+		id uint64,
+		params *types.TransitionParams,
+	) (types.User, error)
+	Graduate(
+		ctx context.Context,
+		id uint64,
+		params *types.TransitionParams,
+	) (types.User, error)
+	FetchHistory(ctx context.Context, id uint64) ([]types.StatusTransition, error)
+	FetchDue(
+		ctx context.Context,
+		status types.Status,
+		startedBefore time.Time,
+	) ([]types.User, error)
+	UploadAvatar(
+		ctx context.Context,
+		id uint64,
+		fileheader *multipart.FileHeader,
+	) (types.User, error)
+	DeleteAvatar(ctx context.Context, id uint64) error
+	FetchProfile(ctx context.Context, id uint64) (types.Profile, error)
+	UpdateProfile(
+		ctx context.Context,
+		id uint64,
+		params *types.ProfileParams,
+	) (types.Profile, error)
+	FetchDirectory(ctx context.Context) ([]types.DirectoryEntry, error)
+	SyncGoogle(ctx context.Context, id uint64, params *types.GoogleProfileParams) error
 	Delete(ctx context.Context, id uint64) error
 }
 
 type usecase struct {
-	store repository.IUserStorage
-	log   *zerolog.Logger
+	store   repository.IUserStorage
+	objects repository.IObjectStorage
+	log     *zerolog.Logger
 }
 
 func NewUserUsecase(
 	store repository.IUserStorage,
+	objects repository.IObjectStorage,
 	log *zerolog.Logger,
 ) IUserUsecase {
-	return &usecase{store, log}
+	return &usecase{store, objects, log}
 }
 
 func (u *usecase) Register(
 	ctx context.Context,
 	user *types.CreateUserParams,
 ) (types.User, error) {
+	if err := normalizeStatus(user); err != nil {
+		return types.User{}, err
+	}
 	id, err := u.store.Create(ctx, user)
 	if err != nil {
 		if errors.Is(repository.ErrDuplicateRow, err) {
@@ -68,6 +121,7 @@ func (u *usecase) Register(
 		Username:            user.Username,
 		Fullname:            user.Fullname,
 		IsMember:            user.IsMember,
+		Status:              user.Status,
 		InternshipStartDate: user.InternshipStartDate,
 	}, nil
 }
@@ -109,6 +163,9 @@ func (u *usecase) RegisterBulkCSV(
 			)
 		}
 		users[i].InternshipStartDate = _time
+		if err := normalizeStatus(&users[i]); err != nil {
+			return err
+		}
 	}
 	if err := u.store.CreateBulk(ctx, users); err != nil {
 		if errors.Is(repository.ErrDuplicateRow, err) {
@@ -133,7 +190,223 @@ func (u *usecase) Fetch(ctx context.Context, id uint64) (types.User, error) {
 		}
 		return types.User{}, fmt.Errorf("fetch user by id: %w", err)
 	}
-	return user.DTO(), nil
+	return u.dto(user), nil
+}
+
+func (u *usecase) FetchByEmail(ctx context.Context, email string) (types.User, error) {
+	user, err := u.store.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(repository.ErrNoRow, err) {
+			return types.User{}, &Error{
+				Code:    http.StatusNotFound,
+				Message: msgUserNotFound,
+			}
+		}
+		return types.User{}, fmt.Errorf("fetch user by email: %w", err)
+	}
+	return u.dto(user), nil
+}
+
+func (u *usecase) FetchList(ctx context.Context) ([]types.User, error) {
+	users, err := u.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch users: %w", err)
+	}
+	dtos := make([]types.User, len(users))
+	for i, user := range users {
+		dtos[i] = u.dto(user)
+	}
+	return dtos, nil
+}
+
+func (u *usecase) FetchBatch(
+	ctx context.Context,
+	params *types.BatchGetUsersParams,
+) (types.BatchUsers, error) {
+	size := len(params.IDs) + len(params.Emails)
+	if size == 0 || size > maxBatchSize {
+		return types.BatchUsers{}, &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgBatchSize,
+		}
+	}
+	users, err := u.store.ListBatch(ctx, params.IDs, params.Emails)
+	if err != nil {
+		return types.BatchUsers{}, fmt.Errorf("fetch users in batch: %w", err)
+	}
+	foundIDs := make(map[uint64]bool, len(users))
+	foundEmails := make(map[string]bool, len(users))
+	batch := types.BatchUsers{
+		Users: make([]types.User, len(users)),
+		Missing: types.BatchMissing{
+			IDs:    []uint64{},
+			Emails: []string{},
+		},
+	}
+	for i, user := range users {
+		batch.Users[i] = u.dto(user)
+		foundIDs[user.ID] = true
+		foundEmails[user.Email] = true
+	}
+	for _, id := range params.IDs {
+		if !foundIDs[id] {
+			batch.Missing.IDs = append(batch.Missing.IDs, id)
+		}
+	}
+	for _, email := range params.Emails {
+		if !foundEmails[email] {
+			batch.Missing.Emails = append(batch.Missing.Emails, email)
+		}
+	}
+	return batch, nil
+}
+
+func (u *usecase) Search(ctx context.Context, query string) ([]types.UserSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minQueryLength {
+		return nil, &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: msgQueryTooShort,
+		}
+	}
+	results, err := u.store.Search(ctx, query, searchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	dtos := make([]types.UserSearchResult, len(results))
+	for i, result := range results {
+		dtos[i] = result.DTO()
+		dtos[i].User = u.dto(result.User)
+		dtos[i].Highlight = highlight(result.Matched, query)
+	}
+	return dtos, nil
+}
+
+// promotions and graduations map each status to the one it may move to.
+var (
+	promotions = map[types.Status]types.Status{
+		types.StatusApplicant: types.StatusIntern,
+		types.StatusIntern:    types.StatusMember,
+	}
+	graduations = map[types.Status]types.Status{
+		types.StatusIntern: types.StatusAlumni,
+		types.StatusMember: types.StatusAlumni,
+	}
+)
+
+func (u *usecase) Promote(
+	ctx context.Context,
+	id uint64,
+	params *types.TransitionParams,
+) (types.User, error) {
+	return u.transition(ctx, id, params, promotions)
+}
+
+func (u *usecase) Graduate(
+	ctx context.Context,
+	id uint64,
+	params *types.TransitionParams,
+) (types.User, error) {
+	return u.transition(ctx, id, params, graduations)
+}
+
+// transition moves the user along the given allowed transitions. Becoming an
+// intern starts the internship and leaving it ends the internship, on the
+// given date or today.
+func (u *usecase) transition(
+	ctx context.Context,
+	id uint64,
+	params *types.TransitionParams,
+	allowed map[types.Status]types.Status,
+) (types.User, error) {
+	user, err := u.Fetch(ctx, id)
+	if err != nil {
+		return types.User{}, err
+	}
+	to, ok := allowed[user.Status]
+	if !ok {
+		return types.User{}, &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf(msgInvalidTransition, user.Status),
+		}
+	}
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if params.Date != nil {
+		date = *params.Date
+	}
+	update := &types.UpdateStatusParams{
+		UserID: id,
+		From:   user.Status,
+		To:     to,
+		Reason: params.Reason,
+	}
+	switch {
+	case to == types.StatusIntern:
+		update.InternshipStartDate = &date
+	case user.Status == types.StatusIntern:
+		update.InternshipEndDate = &date
+	}
+	if err := u.store.Transition(ctx, update); err != nil {
+		if errors.Is(err, repository.ErrNoRowAffected) {
+			return types.User{}, &Error{
+				Code:    http.StatusConflict,
+				Message: msgStatusChanged,
+			}
+		}
+		return types.User{}, fmt.Errorf("transition user status: %w", err)
+	}
+	return u.Fetch(ctx, id)
+}
+
+func (u *usecase) FetchHistory(ctx context.Context, id uint64) ([]types.StatusTransition, error) {
+	if _, err := u.Fetch(ctx, id); err != nil {
+		return nil, err
+	}
+	transitions, err := u.store.ListTransitions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetch status transitions: %w", err)
+	}
+	dtos := make([]types.StatusTransition, len(transitions))
+	for i, transition := range transitions {
+		dtos[i] = transition.DTO()
+	}
+	return dtos, nil
+}
+
+func (u *usecase) FetchDue(
+	ctx context.Context,
+	status types.Status,
+	startedBefore time.Time,
+) ([]types.User, error) {
+	users, err := u.store.ListDue(ctx, status, startedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("fetch due users: %w", err)
+	}
+	dtos := make([]types.User, len(users))
+	for i, user := range users {
+		dtos[i] = u.dto(user)
+	}
+	return dtos, nil
+}
+
+// normalizeStatus keeps IsMember in line with the given status. Callers that
+// predate statuses leave it empty and get one derived from IsMember.
+func normalizeStatus(user *types.CreateUserParams) error {
+	switch user.Status {
+	case "":
+		user.Status = types.StatusIntern
+		if user.IsMember {
+			user.Status = types.StatusMember
+		}
+	case types.StatusApplicant, types.StatusIntern, types.StatusMember, types.StatusAlumni:
+		user.IsMember = user.Status == types.StatusMember
+	default:
+		return &Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf(msgUnknownStatus, user.Status),
+		}
+	}
+	return nil
 }
 
 func (u *usecase) Delete(ctx context.Context, id uint64) error {
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Lab-ICN/backend/user-service/repository"
+	"github.com/Lab-ICN/backend/user-service/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUserUsecase(store repository.IUserStorage) IUserUsecase {
+	log := zerolog.Nop()
+	return NewUserUsecase(store, nil, &log)
+}
+
+// newUserWithStatus registers a user in status, whose internship started on
+// the first of 2024.
+func newUserWithStatus(t *testing.T, store repository.IUserStorage, status types.Status) uint64 {
+	id, err := store.Create(context.Background(), &types.CreateUserParams{
+		Email:               string(status) + "@example.com",
+		Username:            string(status),
+		Fullname:            "A " + string(status),
+		InternshipStartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status:              status,
+	})
+	assert.Nil(t, err)
+	return id
+}
+
+func TestTransitions(t *testing.T) {
+	date := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from     types.Status
+		graduate bool
+		to       types.Status
+		code     int
+	}{
+		{"promote applicant", types.StatusApplicant, false, types.StatusIntern, 0},
+		{"promote intern", types.StatusIntern, false, types.StatusMember, 0},
+		{"promote member", types.StatusMember, false, "", http.StatusConflict},
+		{"promote alumni", types.StatusAlumni, false, "", http.StatusConflict},
+		{"graduate applicant", types.StatusApplicant, true, "", http.StatusConflict},
+		{"graduate intern", types.StatusIntern, true, types.StatusAlumni, 0},
+		{"graduate member", types.StatusMember, true, types.StatusAlumni, 0},
+		{"graduate alumni", types.StatusAlumni, true, "", http.StatusConflict},
+	}
+	for _, tc := range tests {
+		ctx := context.Background()
+		store := repository.NewUserFake()
+		u := newUserUsecase(store)
+		id := newUserWithStatus(t, store, tc.from)
+		move := u.Promote
+		if tc.graduate {
+			move = u.Graduate
+		}
+		user, err := move(ctx, id, &types.TransitionParams{Reason: "review", Date: &date})
+		history, historyErr := u.FetchHistory(ctx, id)
+		assert.Nil(t, historyErr, tc.name)
+		if tc.code != 0 {
+			uscErr := new(Error)
+			assert.True(t, errors.As(err, &uscErr), tc.name)
+			assert.Equal(t, tc.code, uscErr.Code, tc.name)
+			assert.Empty(t, history, "%s: refused transitions leave no history", tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.to, user.Status, tc.name)
+		assert.Equal(t, tc.to == types.StatusMember, user.IsMember, tc.name)
+		switch {
+		case tc.to == types.StatusIntern:
+			assert.Equal(t, date, user.InternshipStartDate, "%s: becoming an intern starts the internship", tc.name)
+		case tc.from == types.StatusIntern:
+			assert.Equal(t, &date, user.InternshipEndDate, "%s: leaving the internship ends it", tc.name)
+		default:
+			assert.Nil(t, user.InternshipEndDate, tc.name)
+		}
+		assert.Len(t, history, 1, tc.name)
+		assert.Equal(t, tc.from, history[0].FromStatus, tc.name)
+		assert.Equal(t, tc.to, history[0].ToStatus, tc.name)
+		assert.Equal(t, "review", history[0].Reason, tc.name)
+	}
+}
+
+// racingStorage has the user change status between the usecase reading and
+// transitioning them.
+type racingStorage struct {
+	repository.IUserStorage
+}
+
+func (s racingStorage) Transition(ctx context.Context, params *types.UpdateStatusParams) error {
+	if err := s.IUserStorage.Transition(ctx, &types.UpdateStatusParams{
+		UserID: params.UserID,
+		From:   params.From,
+		To:     params.To,
+	}); err != nil {
+		return err
+	}
+	return s.IUserStorage.Transition(ctx, params)
+}
+
+func TestTransitionRace(t *testing.T) {
+	ctx := context.Background()
+	store := racingStorage{repository.NewUserFake()}
+	u := newUserUsecase(store)
+	id := newUserWithStatus(t, store, types.StatusIntern)
+	_, err := u.Promote(ctx, id, &types.TransitionParams{})
+	uscErr := new(Error)
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusConflict, uscErr.Code)
+	assert.Equal(t, msgStatusChanged, uscErr.Message)
+
+	_, err = u.Promote(ctx, 404, &types.TransitionParams{})
+	assert.True(t, errors.As(err, &uscErr))
+	assert.Equal(t, http.StatusNotFound, uscErr.Code)
+}